@@ -7,7 +7,7 @@ import (
 	"time"
 )
 
-func testWorker(ctx *mycontext.Context, wg *sync.WaitGroup, i int, resultChan chan string) {
+func testWorker(ctx mycontext.Context, wg *sync.WaitGroup, i int, resultChan chan string) {
 	defer wg.Done()
 
 	select {
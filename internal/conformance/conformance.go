@@ -0,0 +1,324 @@
+package conformance
+
+import (
+	"my_concurency/internal/linearizability"
+	"my_concurency/internal/mylocker"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+/*
+Run прогоняет общий набор проверок для реализации mylocker.Locker.
+newLocker должен каждый раз возвращать новый свободный мьютекс.
+Подключается одной строкой из тестов пакета с мьютексом:
+
+	func TestMutex_Conformance(t *testing.T) {
+		conformance.Run(t, func() mylocker.Locker { return &Mutex{} })
+	}
+
+Если мьютекс умеет LockContext, дополнительно прогоняются проверки
+прерываемого ожидания (context.go), если TryLockFor и TryLockUntil -
+ожидания с пределом по времени (timed.go).
+
+Проверки рассчитаны на запуск с -race: гонки на обычных переменных
+под мьютексом ловит именно он
+*/
+func Run(t *testing.T, newLocker func() mylocker.Locker) {
+	t.Run("LockUnlock", func(t *testing.T) { testLockUnlock(t, newLocker()) })
+	t.Run("MutualExclusion", func(t *testing.T) { testMutualExclusion(t, newLocker()) })
+	t.Run("TryLockOnHeld", func(t *testing.T) { testTryLockOnHeld(t, newLocker()) })
+	t.Run("TryLockAfterUnlock", func(t *testing.T) { testTryLockAfterUnlock(t, newLocker()) })
+	t.Run("TryLockExclusion", func(t *testing.T) { testTryLockExclusion(t, newLocker()) })
+	t.Run("Stress", func(t *testing.T) { testStress(t, newLocker()) })
+	t.Run("HappensBefore", func(t *testing.T) { testHappensBefore(t, newLocker()) })
+	t.Run("NoGoroutineLeak", func(t *testing.T) { testNoGoroutineLeak(t, newLocker) })
+	t.Run("Linearizable", func(t *testing.T) { testLinearizable(t, newLocker) })
+
+	if _, ok := newLocker().(contextLocker); ok {
+		t.Run("LockContext", func(t *testing.T) { runContext(t, newLocker) })
+	}
+	if _, ok := newLocker().(timedLocker); ok {
+		t.Run("Timed", func(t *testing.T) { runTimed(t, newLocker) })
+	}
+}
+
+func testLockUnlock(t *testing.T, mu mylocker.Locker) {
+	for i := 0; i < 10; i++ {
+		mu.Lock()
+		mu.Unlock()
+	}
+}
+
+// Внутри критической секции никогда не бывает больше одной горутины
+func testMutualExclusion(t *testing.T, mu mylocker.Locker) {
+	var inside, violations atomic.Int32
+	var wg sync.WaitGroup
+
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				mu.Lock()
+				if inside.Add(1) != 1 {
+					violations.Add(1)
+				}
+				runtime.Gosched()
+				inside.Add(-1)
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := violations.Load(); n != 0 {
+		t.Errorf("Critical section was entered concurrently %d times", n)
+	}
+}
+
+// TryLock из другой горутины не захватывает занятый мьютекс
+func testTryLockOnHeld(t *testing.T, mu mylocker.Locker) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	acquired := make(chan bool)
+	go func() {
+		ok := mu.TryLock()
+		if ok {
+			mu.Unlock()
+		}
+		acquired <- ok
+	}()
+	if <-acquired {
+		t.Error("TryLock should fail while another goroutine holds the mutex")
+	}
+}
+
+func testTryLockAfterUnlock(t *testing.T, mu mylocker.Locker) {
+	if !mu.TryLock() {
+		t.Fatal("TryLock should succeed on a fresh mutex")
+	}
+	mu.Unlock()
+
+	mu.Lock()
+	mu.Unlock()
+	if !mu.TryLock() {
+		t.Fatal("TryLock should succeed after Unlock")
+	}
+	mu.Unlock()
+
+	// после TryLock мьютекс снова доступен для Lock
+	done := make(chan struct{})
+	go func() {
+		mu.Lock()
+		mu.Unlock()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Lock should succeed after TryLock and Unlock")
+	}
+}
+
+// Одновременные TryLock: успешные попытки не пересекаются
+func testTryLockExclusion(t *testing.T, mu mylocker.Locker) {
+	var inside, violations, successes atomic.Int32
+	var wg sync.WaitGroup
+
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 500; i++ {
+				if !mu.TryLock() {
+					runtime.Gosched()
+					continue
+				}
+				successes.Add(1)
+				if inside.Add(1) != 1 {
+					violations.Add(1)
+				}
+				inside.Add(-1)
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := violations.Load(); n != 0 {
+		t.Errorf("TryLock let %d goroutines in concurrently", n)
+	}
+	if successes.Load() == 0 {
+		t.Error("At least one TryLock should succeed")
+	}
+}
+
+// Lock и TryLock вперемешку, счетчик без атомиков: потерянное обновление или гонка под -race
+func testStress(t *testing.T, mu mylocker.Locker) {
+	const goroutines, iterations = 32, 300
+	var counter int
+	var tries atomic.Int64
+	var wg sync.WaitGroup
+
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < iterations; i++ {
+				if g%4 == 0 && mu.TryLock() {
+					tries.Add(1)
+				} else {
+					mu.Lock()
+				}
+				counter++
+				mu.Unlock()
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	if expected := goroutines * iterations; counter != expected {
+		t.Errorf("Expected %d, got %d (TryLock succeeded %d times)", expected, counter, tries.Load())
+	}
+}
+
+/*
+Записи, сделанные до Unlock, видны после следующего Lock. Данные -
+обычные переменные: без отношения happens-before их поймает -race,
+а без него читатель увидит несогласованную пару
+*/
+func testHappensBefore(t *testing.T, mu mylocker.Locker) {
+	var a, b int
+	var published bool
+	var payload [8]int
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 1; i <= 1000; i++ {
+			mu.Lock()
+			a = i
+			b = i
+			mu.Unlock()
+		}
+
+		mu.Lock()
+		for i := range payload {
+			payload[i] = i + 1
+		}
+		published = true
+		mu.Unlock()
+	}()
+
+	for {
+		mu.Lock()
+		if a != b {
+			mu.Unlock()
+			t.Fatalf("Reader saw a torn update: a=%d b=%d", a, b)
+		}
+		done := published
+		data := payload
+		mu.Unlock()
+
+		if done {
+			for i, v := range data {
+				if v != i+1 {
+					t.Fatalf("Data written before Unlock is not visible: %v", data)
+				}
+			}
+			break
+		}
+		runtime.Gosched()
+	}
+	wg.Wait()
+}
+
+// Работа с мьютексом не оставляет после себя горутин
+func testNoGoroutineLeak(t *testing.T, newLocker func() mylocker.Locker) {
+	before := runtime.NumGoroutine()
+
+	for round := 0; round < 10; round++ {
+		mu := newLocker()
+		var wg sync.WaitGroup
+		for g := 0; g < 8; g++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := 0; i < 50; i++ {
+					mu.Lock()
+					mu.Unlock()
+					if mu.TryLock() {
+						mu.Unlock()
+					}
+				}
+			}()
+		}
+		wg.Wait()
+	}
+
+	// завершившиеся горутины рантайм убирает не мгновенно
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before {
+		if time.Now().After(deadline) {
+			t.Errorf("Goroutine leak: %d before, %d after", before, runtime.NumGoroutine())
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+/*
+История Lock/Unlock/TryLock из нескольких горутин линеаризуема относительно
+последовательного мьютекса. Ловит то, что не видно по счетчику: TryLock,
+вернувший false на свободном мьютексе, или true на занятом.
+Истории короткие, чтобы перебор в Check оставался быстрым, зато их много
+*/
+func testLinearizable(t *testing.T, newLocker func() mylocker.Locker) {
+	const rounds, goroutines, iterations = 20, 4, 25
+
+	for round := 0; round < rounds; round++ {
+		mu := newLocker()
+		rec := linearizability.NewRecorder()
+		var wg sync.WaitGroup
+		for g := 0; g < goroutines; g++ {
+			c := rec.Client()
+			wg.Add(1)
+			go func(g int) {
+				defer wg.Done()
+				for i := 0; i < iterations; i++ {
+					if (g+i)%3 == 0 {
+						c.Invoke(linearizability.TryLock)
+						ok := mu.TryLock()
+						c.Return(ok)
+						if !ok {
+							runtime.Gosched()
+							continue
+						}
+					} else {
+						c.Invoke(linearizability.Lock)
+						mu.Lock()
+						c.Return(nil)
+					}
+					runtime.Gosched()
+					c.Invoke(linearizability.Unlock)
+					mu.Unlock()
+					c.Return(nil)
+				}
+			}(g)
+		}
+		wg.Wait()
+
+		history := rec.History()
+		if !linearizability.Check(linearizability.MutexModel(), history) {
+			for _, op := range history {
+				t.Log(op)
+			}
+			t.Fatalf("Round %d: history of %d operations is not linearizable", round, len(history))
+		}
+	}
+}
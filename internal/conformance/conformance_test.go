@@ -0,0 +1,11 @@
+package conformance
+
+import (
+	"my_concurency/internal/mylocker"
+	"testing"
+)
+
+// Эталон: sync.Mutex обязан проходить весь набор
+func TestRun_SyncMutex(t *testing.T) {
+	Run(t, mylocker.Sync)
+}
@@ -0,0 +1,207 @@
+package conformance
+
+import (
+	"context"
+	"my_concurency/internal/mycontext"
+	"my_concurency/internal/mylocker"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Мьютекс, ожидание которого можно прервать контекстом
+type contextLocker interface {
+	mylocker.Locker
+	LockContext(ctx context.Context) error
+}
+
+/*
+Проверки LockContext, Run запускает их сам, если мьютекс его умеет.
+Пределы времени щедрые: проверяется, что ожидание прерывается,
+а не то, насколько быстро
+*/
+func runContext(t *testing.T, newLocker func() mylocker.Locker) {
+	lock := func() contextLocker { return newLocker().(contextLocker) }
+
+	t.Run("Free", func(t *testing.T) { testLockContextFree(t, lock()) })
+	t.Run("Cancelled", func(t *testing.T) { testLockContextCancelled(t, lock()) })
+	t.Run("Timeout", func(t *testing.T) { testLockContextTimeout(t, lock()) })
+	t.Run("StdContext", func(t *testing.T) { testLockContextStd(t, lock()) })
+	t.Run("ConcurrentAccess", func(t *testing.T) { testLockContextConcurrent(t, lock()) })
+	t.Run("NoGoroutineLeak", func(t *testing.T) { testLockContextNoLeak(t, lock()) })
+}
+
+func testLockContextFree(t *testing.T, mu contextLocker) {
+	if err := mu.LockContext(context.Background()); err != nil {
+		t.Fatalf("LockContext should succeed on unlocked mutex, got %v", err)
+	}
+
+	if tryLockElsewhere(mu) {
+		t.Error("Mutex should be locked after LockContext")
+	}
+	mu.Unlock()
+}
+
+func testLockContextCancelled(t *testing.T, mu contextLocker) {
+	ctx, cancel := mycontext.WithCancel(mycontext.Background())
+	cancel()
+
+	if err := mu.LockContext(ctx); err != mycontext.Canceled {
+		t.Errorf("Expected Canceled error, got %v", err)
+	}
+
+	// Мьютекс не должен остаться захваченным
+	if !mu.TryLock() {
+		t.Fatal("LockContext with cancelled context should not lock the mutex")
+	}
+	mu.Unlock()
+}
+
+func testLockContextTimeout(t *testing.T, mu contextLocker) {
+	mu.Lock()
+
+	ctx, cancel := mycontext.WithTimeout(mycontext.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	result := make(chan error)
+	go func() {
+		result <- mu.LockContext(ctx)
+	}()
+	select {
+	case err := <-result:
+		if err != mycontext.DeadlineExceeded {
+			t.Errorf("Expected DeadlineExceeded error, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("LockContext should give up after the deadline")
+	}
+
+	mu.Unlock()
+
+	// Брошенное ожидание не должно мешать следующим захватам
+	done := make(chan struct{})
+	go func() {
+		mu.Lock()
+		mu.Unlock()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Mutex should be usable after abandoned LockContext")
+	}
+}
+
+func testLockContextStd(t *testing.T, mu contextLocker) {
+	mu.Lock()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	result := make(chan error)
+	go func() {
+		result <- mu.LockContext(ctx)
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+
+	select {
+	case err := <-result:
+		if err != context.Canceled {
+			t.Errorf("Expected context.Canceled error, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("LockContext should return after cancellation")
+	}
+	mu.Unlock()
+}
+
+// Половина горутин сдается почти сразу: брошенные ожидания не ломают счетчик и не оставляют мьютекс занятым
+func testLockContextConcurrent(t *testing.T, mu contextLocker) {
+	const goroutines = 50
+	var counter, acquired int
+	var wg sync.WaitGroup
+
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			mu.Lock()
+			counter++
+			mu.Unlock()
+
+			timeout := 10 * time.Second
+			if g%2 == 0 {
+				timeout = time.Microsecond
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			defer cancel()
+			if mu.LockContext(ctx) == nil {
+				counter++
+				acquired++
+				runtime.Gosched()
+				mu.Unlock()
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	if !mu.TryLock() {
+		t.Fatal("Mutex should be free after all goroutines finished")
+	}
+	defer mu.Unlock()
+	if counter != goroutines+acquired {
+		t.Errorf("Expected %d, got %d", goroutines+acquired, counter)
+	}
+}
+
+// Прерванные ожидания на занятом мьютексе не оставляют горутин, пока он занят
+func testLockContextNoLeak(t *testing.T, mu contextLocker) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	before := runtime.NumGoroutine()
+	// ждем не из горутины-владельца: мьютекс с проверкой владельца запрещает повторный захват
+	acquired := make(chan int)
+	go func() {
+		n := 0
+		for i := 0; i < 200; i++ {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Microsecond)
+			if mu.LockContext(ctx) == nil {
+				n++
+			}
+			cancel()
+		}
+		acquired <- n
+	}()
+	if n := <-acquired; n != 0 {
+		t.Fatalf("LockContext should fail on a held mutex, succeeded %d times", n)
+	}
+	expectGoroutines(t, before)
+}
+
+// Ждет, пока число горутин вернется к before: завершившиеся рантайм убирает не мгновенно
+func expectGoroutines(t *testing.T, before int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before {
+		if time.Now().After(deadline) {
+			t.Errorf("Goroutine leak: %d before, %d after", before, runtime.NumGoroutine())
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// TryLock из другой горутины, как в testTryLockOnHeld: владелец реентерабельного мьютекса взял бы его снова
+func tryLockElsewhere(mu mylocker.Locker) bool {
+	result := make(chan bool)
+	go func() {
+		ok := mu.TryLock()
+		if ok {
+			mu.Unlock()
+		}
+		result <- ok
+	}()
+	return <-result
+}
@@ -0,0 +1,108 @@
+package conformance
+
+import (
+	"my_concurency/internal/mylocker"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// Мьютекс, который можно ждать ограниченное время
+type timedLocker interface {
+	mylocker.Locker
+	TryLockFor(d time.Duration) bool
+	TryLockUntil(deadline time.Time) bool
+}
+
+/*
+Проверки TryLockFor и TryLockUntil, Run запускает их сам, если мьютекс их умеет.
+На занятом мьютексе ждем не из горутины-владельца, как в testLockContextNoLeak
+*/
+func runTimed(t *testing.T, newLocker func() mylocker.Locker) {
+	lock := func() timedLocker { return newLocker().(timedLocker) }
+
+	t.Run("TryLockFor", func(t *testing.T) { testTryLockFor(t, lock()) })
+	t.Run("ReleasedInTime", func(t *testing.T) { testTryLockForReleased(t, lock()) })
+	t.Run("PastDeadline", func(t *testing.T) { testTryLockUntilPast(t, lock()) })
+	t.Run("NoGoroutineLeak", func(t *testing.T) { testTryLockForNoLeak(t, lock()) })
+}
+
+// Выполняет f в другой горутине и возвращает ее результат
+func elsewhere(f func() bool) bool {
+	result := make(chan bool)
+	go func() { result <- f() }()
+	return <-result
+}
+
+func testTryLockFor(t *testing.T, mu timedLocker) {
+	if !mu.TryLockFor(10 * time.Millisecond) {
+		t.Fatal("TryLockFor should succeed on unlocked mutex")
+	}
+
+	start := time.Now()
+	if elsewhere(func() bool { return mu.TryLockFor(20 * time.Millisecond) }) {
+		t.Error("TryLockFor should fail on locked mutex")
+	}
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Errorf("TryLockFor gave up too early: %v", elapsed)
+	}
+
+	mu.Unlock()
+}
+
+func testTryLockForReleased(t *testing.T, mu timedLocker) {
+	mu.Lock()
+
+	// владелец отпускает мьютекс, пока другая горутина его ждет
+	result := make(chan bool)
+	go func() {
+		ok := mu.TryLockFor(5 * time.Second)
+		if ok {
+			mu.Unlock()
+		}
+		result <- ok
+	}()
+	time.Sleep(10 * time.Millisecond)
+	mu.Unlock()
+
+	if !<-result {
+		t.Fatal("TryLockFor should succeed when mutex is released in time")
+	}
+}
+
+func testTryLockUntilPast(t *testing.T, mu timedLocker) {
+	past := time.Now().Add(-time.Second)
+
+	mu.Lock()
+	if elsewhere(func() bool { return mu.TryLockUntil(past) }) {
+		t.Error("TryLockUntil should fail on locked mutex with past deadline")
+	}
+	mu.Unlock()
+
+	if !mu.TryLockUntil(past) {
+		t.Error("TryLockUntil should still take a free mutex with past deadline")
+	}
+	mu.Unlock()
+}
+
+// Сотни истекших TryLockFor на занятом мьютексе не оставляют горутин
+func testTryLockForNoLeak(t *testing.T, mu timedLocker) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	before := runtime.NumGoroutine()
+	acquired := make(chan int)
+	go func() {
+		n := 0
+		for i := 0; i < 200; i++ {
+			if mu.TryLockFor(time.Microsecond) {
+				n++
+			}
+		}
+		acquired <- n
+	}()
+	if n := <-acquired; n != 0 {
+		t.Fatalf("TryLockFor should fail on a held mutex, succeeded %d times", n)
+	}
+	expectGoroutines(t, before)
+}
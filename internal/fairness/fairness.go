@@ -0,0 +1,141 @@
+package fairness
+
+import (
+	"fmt"
+	"my_concurency/internal/spin"
+	"runtime"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+/*
+Config - параметры прогона: Goroutines горутин в течение Duration
+захватывают мьютекс, держат его Work итераций пустой работы и сразу
+встают в очередь снова
+*/
+type Config struct {
+	Goroutines int
+	Duration   time.Duration
+	Work       int
+	/*
+		Yield: после Unlock уступаем процессор. При GOMAXPROCS меньше числа
+		горутин без этого одна горутина захватывает мьютекс весь свой квант,
+		пока остальные даже не встали в очередь, и мерить приходится планировщик
+	*/
+	Yield bool
+}
+
+// Report - результат прогона
+type Report struct {
+	// Counts[i] - сколько раз горутина i захватила мьютекс
+	Counts []int
+	Total  int
+	/*
+		индекс справедливости Джейна: (sum x)^2 / (n * sum x^2).
+		1 - все захватывали поровну, 1/n - все досталось одной горутине
+	*/
+	Jain float64
+	// самая длинная серия захватов подряд одной и той же горутиной
+	MaxConsecutive int
+	// перцентили времени от вызова Lock до захвата
+	P50, P99, P999 time.Duration
+}
+
+func (r Report) String() string {
+	return fmt.Sprintf("total=%d jain=%.3f max-consecutive=%d p50=%v p99=%v p999=%v counts=%v",
+		r.Total, r.Jain, r.MaxConsecutive, r.P50, r.P99, r.P999, r.Counts)
+}
+
+/*
+Run гоняет cfg.Goroutines горутин против mu и собирает Report.
+Подходит любой sync.Locker, в том числе все реализации mylocker.Locker
+*/
+func Run(mu sync.Locker, cfg Config) Report {
+	counts := make([]int, cfg.Goroutines)
+	waits := make([][]time.Duration, cfg.Goroutines)
+
+	// последний владелец и длина его серии, меняются только под mu
+	last, run, maxRun := -1, 0, 0
+
+	var stop atomic.Bool
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+
+	for g := 0; g < cfg.Goroutines; g++ {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			<-start
+			for !stop.Load() {
+				begin := time.Now()
+				mu.Lock()
+				waits[id] = append(waits[id], time.Since(begin))
+
+				if last == id {
+					run++
+				} else {
+					last, run = id, 1
+				}
+				maxRun = max(maxRun, run)
+				spin.Pause(cfg.Work)
+
+				mu.Unlock()
+				counts[id]++
+				if cfg.Yield {
+					runtime.Gosched()
+				}
+			}
+		}(g)
+	}
+
+	// все горутины стартуют одновременно
+	close(start)
+	time.Sleep(cfg.Duration)
+	stop.Store(true)
+	wg.Wait()
+
+	var all []time.Duration
+	for _, w := range waits {
+		all = append(all, w...)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i] < all[j] })
+
+	total := 0
+	for _, c := range counts {
+		total += c
+	}
+
+	return Report{
+		Counts:         counts,
+		Total:          total,
+		Jain:           Jain(counts),
+		MaxConsecutive: maxRun,
+		P50:            Percentile(all, 0.5),
+		P99:            Percentile(all, 0.99),
+		P999:           Percentile(all, 0.999),
+	}
+}
+
+// Jain - индекс справедливости Джейна для числа захватов, 0 если захватов не было
+func Jain(counts []int) float64 {
+	var sum, squares float64
+	for _, c := range counts {
+		sum += float64(c)
+		squares += float64(c) * float64(c)
+	}
+	if squares == 0 {
+		return 0
+	}
+	return sum * sum / (float64(len(counts)) * squares)
+}
+
+// Percentile берет перцентиль p (0..1) из отсортированного по возрастанию sorted
+func Percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	i := int(float64(len(sorted))*p+0.5) - 1
+	return sorted[min(max(i, 0), len(sorted)-1)]
+}
@@ -0,0 +1,76 @@
+package fairness
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestJain(t *testing.T) {
+	cases := []struct {
+		counts []int
+		want   float64
+	}{
+		{[]int{10, 10, 10, 10}, 1},
+		{[]int{40, 0, 0, 0}, 0.25},
+		{[]int{30, 10}, 0.8},
+		{[]int{0, 0}, 0},
+	}
+	for _, c := range cases {
+		if got := Jain(c.counts); got < c.want-1e-9 || got > c.want+1e-9 {
+			t.Errorf("Jain(%v): expected %v, got %v", c.counts, c.want, got)
+		}
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	sorted := make([]time.Duration, 1000)
+	for i := range sorted {
+		sorted[i] = time.Duration(i + 1)
+	}
+
+	cases := []struct {
+		p    float64
+		want time.Duration
+	}{
+		{0.5, 500},
+		{0.99, 990},
+		{0.999, 999},
+		{1, 1000},
+		{0, 1},
+	}
+	for _, c := range cases {
+		if got := Percentile(sorted, c.p); got != c.want {
+			t.Errorf("Percentile(%v): expected %v, got %v", c.p, c.want, got)
+		}
+	}
+
+	if got := Percentile(nil, 0.5); got != 0 {
+		t.Errorf("Percentile of empty slice should be 0, got %v", got)
+	}
+}
+
+func TestRun(t *testing.T) {
+	var mu sync.Mutex
+	r := Run(&mu, Config{Goroutines: 4, Duration: 20 * time.Millisecond, Work: 10, Yield: true})
+
+	if len(r.Counts) != 4 {
+		t.Fatalf("Expected counts for 4 goroutines, got %v", r.Counts)
+	}
+	sum := 0
+	for _, c := range r.Counts {
+		sum += c
+	}
+	if r.Total == 0 || sum != r.Total {
+		t.Errorf("Total %d should be the sum of counts %v", r.Total, r.Counts)
+	}
+	if r.Jain <= 0 || r.Jain > 1 {
+		t.Errorf("Jain index should be in (0, 1], got %v", r.Jain)
+	}
+	if r.MaxConsecutive < 1 || r.MaxConsecutive > r.Total {
+		t.Errorf("Unexpected max consecutive %d", r.MaxConsecutive)
+	}
+	if r.P50 > r.P99 || r.P99 > r.P999 {
+		t.Errorf("Percentiles should not decrease: %v", r)
+	}
+}
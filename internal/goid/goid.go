@@ -0,0 +1,30 @@
+package goid
+
+import (
+	"bytes"
+	"runtime"
+	"strconv"
+)
+
+/*
+ID возвращает номер текущей горутины. Рантайм его не отдает, поэтому
+разбираем первую строку runtime.Stack: "goroutine 18 [running]:".
+Вызов стоит порядка микросекунды, годится для отладочных проверок,
+но не для быстрого пути
+*/
+func ID() int64 {
+	var buf [64]byte
+	n := runtime.Stack(buf[:], false)
+	line := bytes.TrimPrefix(buf[:n], []byte("goroutine "))
+
+	end := bytes.IndexByte(line, ' ')
+	if end < 0 {
+		panic("goid: unexpected runtime.Stack format: " + string(buf[:n]))
+	}
+
+	id, err := strconv.ParseInt(string(line[:end]), 10, 64)
+	if err != nil {
+		panic("goid: unexpected runtime.Stack format: " + string(buf[:n]))
+	}
+	return id
+}
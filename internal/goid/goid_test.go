@@ -0,0 +1,43 @@
+package goid
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestID_Stable(t *testing.T) {
+	id := ID()
+	if id <= 0 {
+		t.Fatalf("Expected positive goroutine id, got %d", id)
+	}
+	if again := ID(); again != id {
+		t.Errorf("ID should not change within a goroutine: %d then %d", id, again)
+	}
+}
+
+func TestID_Unique(t *testing.T) {
+	const goroutines = 100
+	ids := make(chan int64, goroutines)
+	var wg sync.WaitGroup
+
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ids <- ID()
+		}()
+	}
+	wg.Wait()
+	close(ids)
+
+	seen := make(map[int64]bool)
+	for id := range ids {
+		if seen[id] {
+			t.Errorf("Goroutine id %d returned twice", id)
+		}
+		seen[id] = true
+	}
+	if seen[ID()] {
+		t.Error("Test goroutine id should differ from spawned goroutines")
+	}
+}
@@ -0,0 +1,126 @@
+package interleave
+
+import "sync/atomic"
+
+/*
+Обертки над sync/atomic с тем же набором методов. Перед каждой операцией
+вызывается Yield, и если горутина запущена планировщиком Explore, именно
+здесь он решает, кто выполнится следующим. Вне Explore это обычные атомики
+плюс одна атомарная загрузка.
+
+Пакеты с мьютексами собираются против них под тегом interleave:
+
+	//go:build interleave
+	type atomicInt64 = interleave.Int64
+*/
+
+type Bool struct {
+	v atomic.Bool
+}
+
+func (x *Bool) Load() bool {
+	Yield("Bool.Load")
+	return x.v.Load()
+}
+
+func (x *Bool) Store(val bool) {
+	Yield("Bool.Store")
+	x.v.Store(val)
+}
+
+func (x *Bool) Swap(new bool) bool {
+	Yield("Bool.Swap")
+	return x.v.Swap(new)
+}
+
+func (x *Bool) CompareAndSwap(old, new bool) bool {
+	Yield("Bool.CompareAndSwap")
+	return x.v.CompareAndSwap(old, new)
+}
+
+type Int32 struct {
+	v atomic.Int32
+}
+
+func (x *Int32) Load() int32 {
+	Yield("Int32.Load")
+	return x.v.Load()
+}
+
+func (x *Int32) Store(val int32) {
+	Yield("Int32.Store")
+	x.v.Store(val)
+}
+
+func (x *Int32) Add(delta int32) int32 {
+	Yield("Int32.Add")
+	return x.v.Add(delta)
+}
+
+func (x *Int32) Swap(new int32) int32 {
+	Yield("Int32.Swap")
+	return x.v.Swap(new)
+}
+
+func (x *Int32) CompareAndSwap(old, new int32) bool {
+	Yield("Int32.CompareAndSwap")
+	return x.v.CompareAndSwap(old, new)
+}
+
+type Int64 struct {
+	v atomic.Int64
+}
+
+func (x *Int64) Load() int64 {
+	Yield("Int64.Load")
+	return x.v.Load()
+}
+
+func (x *Int64) Store(val int64) {
+	Yield("Int64.Store")
+	x.v.Store(val)
+}
+
+func (x *Int64) Add(delta int64) int64 {
+	Yield("Int64.Add")
+	return x.v.Add(delta)
+}
+
+func (x *Int64) Swap(new int64) int64 {
+	Yield("Int64.Swap")
+	return x.v.Swap(new)
+}
+
+func (x *Int64) CompareAndSwap(old, new int64) bool {
+	Yield("Int64.CompareAndSwap")
+	return x.v.CompareAndSwap(old, new)
+}
+
+type Uint32 struct {
+	v atomic.Uint32
+}
+
+func (x *Uint32) Load() uint32 {
+	Yield("Uint32.Load")
+	return x.v.Load()
+}
+
+func (x *Uint32) Store(val uint32) {
+	Yield("Uint32.Store")
+	x.v.Store(val)
+}
+
+func (x *Uint32) Add(delta uint32) uint32 {
+	Yield("Uint32.Add")
+	return x.v.Add(delta)
+}
+
+func (x *Uint32) Swap(new uint32) uint32 {
+	Yield("Uint32.Swap")
+	return x.v.Swap(new)
+}
+
+func (x *Uint32) CompareAndSwap(old, new uint32) bool {
+	Yield("Uint32.CompareAndSwap")
+	return x.v.CompareAndSwap(old, new)
+}
@@ -0,0 +1,32 @@
+package interleave
+
+import "testing"
+
+// Вне Explore обертки ведут себя как обычные атомики
+func TestAtomics_OutsideExplore(t *testing.T) {
+	var b Bool
+	if !b.CompareAndSwap(false, true) || b.CompareAndSwap(false, true) || !b.Load() {
+		t.Error("Bool should behave like atomic.Bool")
+	}
+	if old := b.Swap(false); !old {
+		t.Error("Bool.Swap should return the old value")
+	}
+
+	var i32 Int32
+	i32.Store(5)
+	if i32.Add(2) != 7 || i32.Swap(1) != 7 || !i32.CompareAndSwap(1, 2) || i32.Load() != 2 {
+		t.Error("Int32 should behave like atomic.Int32")
+	}
+
+	var i64 Int64
+	i64.Store(5)
+	if i64.Add(-2) != 3 || i64.Swap(1) != 3 || !i64.CompareAndSwap(1, 2) || i64.Load() != 2 {
+		t.Error("Int64 should behave like atomic.Int64")
+	}
+
+	var u32 Uint32
+	u32.Store(5)
+	if u32.Add(2) != 7 || u32.Swap(1) != 7 || !u32.CompareAndSwap(1, 2) || u32.Load() != 2 {
+		t.Error("Uint32 should behave like atomic.Uint32")
+	}
+}
@@ -0,0 +1,428 @@
+package interleave
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"my_concurency/internal/goid"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+/*
+Маленький model checker. Explore запускает несколько потоков (горутин)
+теста строго по одному: поток работает до следующей инструментированной
+операции (Yield), там останавливается, и планировщик выбирает, кто пойдет
+дальше. Так каждое чередование атомарных операций воспроизводится точно,
+а найденное плохое чередование можно повторить через Replay.
+
+Ограничение: потоки не должны блокироваться на том, что планировщик
+не видит (каналы, sync.Mutex, ожидание друг друга без атомиков) -
+такой поток считается зависшим и прогон завершается ошибкой
+*/
+
+type Mode int
+
+const (
+	// Systematic перебирает все варианты в первых Depth точках выбора, дальше потоки идут по кругу
+	Systematic Mode = iota
+	// Random на каждой точке выбора берет случайный поток, прогонов ровно Runs
+	Random
+)
+
+const (
+	defaultDepth       = 10
+	defaultRuns        = 10000
+	defaultMaxSteps    = 10000
+	defaultStepTimeout = time.Second
+)
+
+// Config - параметры Explore, нулевые поля заменяются значениями по умолчанию
+type Config struct {
+	Mode  Mode
+	Depth int
+	// предел числа прогонов
+	Runs int
+	Seed uint64
+	// прогон длиннее считается livelock
+	MaxSteps int
+	// сколько ждать поток, заблокированный вне инструментированных операций
+	StepTimeout time.Duration
+}
+
+func (cfg Config) withDefaults() Config {
+	if cfg.Depth <= 0 {
+		cfg.Depth = defaultDepth
+	}
+	if cfg.Runs <= 0 {
+		cfg.Runs = defaultRuns
+	}
+	if cfg.MaxSteps <= 0 {
+		cfg.MaxSteps = defaultMaxSteps
+	}
+	if cfg.StepTimeout <= 0 {
+		cfg.StepTimeout = defaultStepTimeout
+	}
+	return cfg
+}
+
+/*
+Setup готовит новое состояние для каждого прогона и возвращает потоки
+и проверку, которая вызывается после завершения всех потоков (может быть nil).
+Нарушение внутри потока сообщается паникой
+*/
+type Setup func() (threads []func(), check func() error)
+
+// Step - шаг прогона: поток Thread выполнил операцию Op ("start" - первый запуск)
+type Step struct {
+	Thread int
+	Op     string
+}
+
+// Failure - найденное плохое чередование
+type Failure struct {
+	Err error
+	// номер потока на каждом шаге, годится для Replay
+	Schedule []int
+	Trace    []Step
+	// номер прогона, с 1
+	Run int
+}
+
+func (f *Failure) Error() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "interleave: %v (run %d)\nschedule: %v\n", f.Err, f.Run, f.Schedule)
+	for _, step := range f.Trace {
+		fmt.Fprintf(&sb, "  thread %d: %s\n", step.Thread, step.Op)
+	}
+	return sb.String()
+}
+
+type Result struct {
+	Runs int
+	// Systematic перебрал все варианты в пределах Depth
+	Complete bool
+	Failure  *Failure
+}
+
+// ErrLivelock - прогон не закончился за MaxSteps шагов
+var ErrLivelock = errors.New("livelock: execution did not finish")
+
+// Explore перебирает чередования потоков из setup, пока не найдет нарушение
+func Explore(cfg Config, setup Setup) Result {
+	cfg = cfg.withDefaults()
+
+	switch cfg.Mode {
+	case Random:
+		rng := rand.New(rand.NewPCG(cfg.Seed, cfg.Seed^0x9e3779b97f4a7c15))
+		for run := 1; run <= cfg.Runs; run++ {
+			if f := execute(cfg, setup, randomChooser{rng}); f != nil {
+				f.Run = run
+				return Result{Runs: run, Failure: f}
+			}
+		}
+		return Result{Runs: cfg.Runs}
+
+	default:
+		dfs := &dfsChooser{depth: cfg.Depth}
+		for run := 1; run <= cfg.Runs; run++ {
+			dfs.start()
+			if f := execute(cfg, setup, dfs); f != nil {
+				f.Run = run
+				return Result{Runs: run, Failure: f}
+			}
+			if !dfs.next() {
+				return Result{Runs: run, Complete: true}
+			}
+		}
+		return Result{Runs: cfg.Runs}
+	}
+}
+
+// Replay повторяет прогон по расписанию из Failure.Schedule, после его конца потоки идут по кругу
+func Replay(cfg Config, schedule []int, setup Setup) *Failure {
+	if f := execute(cfg.withDefaults(), setup, &replayChooser{schedule: schedule}); f != nil {
+		f.Run = 1
+		return f
+	}
+	return nil
+}
+
+/*
+chooser выбирает поток для шага step. last - поток прошлого шага,
+options - готовые потоки: первым идет last, если он еще не закончил,
+остальные по возрастанию номера
+*/
+type chooser interface {
+	choose(step, last int, options []int) (int, error)
+}
+
+type randomChooser struct {
+	rng *rand.Rand
+}
+
+func (c randomChooser) choose(_, _ int, options []int) (int, error) {
+	return options[c.rng.IntN(len(options))], nil
+}
+
+// По кругу: готовый поток со следующим после last номером
+func roundRobin(last int, options []int) int {
+	next, first := -1, options[0]
+	for _, id := range options {
+		if id > last && (next < 0 || id < next) {
+			next = id
+		}
+		first = min(first, id)
+	}
+	if next >= 0 {
+		return next
+	}
+	return first
+}
+
+/*
+Поиск в глубину по точкам выбора (шагам, где готово больше одного потока).
+prefix - индексы вариантов, которые нужно повторить в начале прогона,
+trail - что выбрано в этом прогоне и сколько было вариантов
+*/
+type dfsChooser struct {
+	depth  int
+	prefix []int
+	trail  []dfsChoice
+}
+
+type dfsChoice struct {
+	index, count int
+}
+
+func (c *dfsChooser) start() {
+	c.trail = c.trail[:0]
+}
+
+func (c *dfsChooser) choose(_, last int, options []int) (int, error) {
+	if len(options) == 1 {
+		return options[0], nil
+	}
+
+	k := len(c.trail)
+	if k >= c.depth {
+		return roundRobin(last, options), nil
+	}
+
+	index := 0
+	if k < len(c.prefix) {
+		index = c.prefix[k]
+		if index >= len(options) {
+			return 0, fmt.Errorf("nondeterministic setup: choice %d has %d options, expected more than %d", k, len(options), index)
+		}
+	}
+	c.trail = append(c.trail, dfsChoice{index: index, count: len(options)})
+	return options[index], nil
+}
+
+// Следующий prefix: сдвигаем самый глубокий выбор, у которого остались варианты
+func (c *dfsChooser) next() bool {
+	for k := len(c.trail) - 1; k >= 0; k-- {
+		if c.trail[k].index+1 < c.trail[k].count {
+			c.prefix = c.prefix[:0]
+			for _, choice := range c.trail[:k] {
+				c.prefix = append(c.prefix, choice.index)
+			}
+			c.prefix = append(c.prefix, c.trail[k].index+1)
+			return true
+		}
+	}
+	return false
+}
+
+type replayChooser struct {
+	schedule []int
+}
+
+func (c *replayChooser) choose(step, last int, options []int) (int, error) {
+	if step >= len(c.schedule) {
+		return roundRobin(last, options), nil
+	}
+	for _, id := range options {
+		if id == c.schedule[step] {
+			return id, nil
+		}
+	}
+	return 0, fmt.Errorf("schedule step %d: thread %d is not runnable", step, c.schedule[step])
+}
+
+type eventKind int
+
+const (
+	yielded eventKind = iota
+	finished
+	panicked
+)
+
+type event struct {
+	kind  eventKind
+	op    string
+	value any
+}
+
+type execution struct {
+	events chan event
+	abort  chan struct{}
+}
+
+type thread struct {
+	id     int
+	exec   *execution
+	resume chan struct{}
+}
+
+var (
+	// число идущих Explore: пока 0, Yield не ищет поток
+	explorers atomic.Int32
+	// номер горутины -> поток
+	registry sync.Map
+)
+
+// errAborted - паника, которой останавливаются потоки прерванного прогона
+var errAborted = errors.New("interleave: execution aborted")
+
+/*
+Yield - точка переключения. Инструментированные атомики вызывают ее
+перед каждой операцией, код под тестом может вызывать и сам, чтобы
+добавить точку выбора между неатомарными шагами. Вне Explore ничего не делает
+*/
+func Yield(op string) {
+	if explorers.Load() == 0 {
+		return
+	}
+	v, ok := registry.Load(goid.ID())
+	if !ok {
+		return
+	}
+	t := v.(*thread)
+
+	select {
+	case t.exec.events <- event{kind: yielded, op: op}:
+	case <-t.exec.abort:
+		panic(errAborted)
+	}
+	select {
+	case <-t.resume:
+	case <-t.exec.abort:
+		panic(errAborted)
+	}
+}
+
+func execute(cfg Config, setup Setup, ch chooser) (failure *Failure) {
+	explorers.Add(1)
+	defer explorers.Add(-1)
+
+	fns, check := setup()
+	exec := &execution{
+		events: make(chan event),
+		abort:  make(chan struct{}),
+	}
+	defer close(exec.abort)
+
+	threads := make([]*thread, len(fns))
+	next := make(map[int]string, len(fns))
+	for i, fn := range fns {
+		t := &thread{id: i, exec: exec, resume: make(chan struct{}, 1)}
+		threads[i] = t
+		next[i] = "start"
+		go t.run(fn)
+	}
+
+	var trace []Step
+	fail := func(err error) *Failure {
+		schedule := make([]int, len(trace))
+		for i, step := range trace {
+			schedule[i] = step.Thread
+		}
+		return &Failure{Err: err, Schedule: schedule, Trace: trace}
+	}
+
+	timer := time.NewTimer(cfg.StepTimeout)
+	defer timer.Stop()
+
+	last := -1
+	for len(next) > 0 {
+		if len(trace) >= cfg.MaxSteps {
+			return fail(fmt.Errorf("%w in %d steps", ErrLivelock, cfg.MaxSteps))
+		}
+
+		id, err := ch.choose(len(trace), last, options(next, last))
+		if err != nil {
+			return fail(err)
+		}
+		trace = append(trace, Step{Thread: id, Op: next[id]})
+		last = id
+		threads[id].resume <- struct{}{}
+
+		timer.Reset(cfg.StepTimeout)
+		select {
+		case ev := <-exec.events:
+			switch ev.kind {
+			case yielded:
+				next[id] = ev.op
+			case finished:
+				delete(next, id)
+			case panicked:
+				return fail(fmt.Errorf("thread %d panicked: %v", id, ev.value))
+			}
+		case <-timer.C:
+			return fail(fmt.Errorf("thread %d blocked outside instrumented operations", id))
+		}
+	}
+
+	if check != nil {
+		if err := check(); err != nil {
+			return fail(err)
+		}
+	}
+	return nil
+}
+
+// Готовые потоки: last первым, если он готов, остальные по возрастанию
+func options(next map[int]string, last int) []int {
+	result := make([]int, 0, len(next))
+	if _, ok := next[last]; ok {
+		result = append(result, last)
+	}
+	for id := 0; len(result) < len(next); id++ {
+		if _, ok := next[id]; ok && id != last {
+			result = append(result, id)
+		}
+	}
+	return result
+}
+
+func (t *thread) run(fn func()) {
+	id := goid.ID()
+	registry.Store(id, t)
+	defer registry.Delete(id)
+
+	defer func() {
+		r := recover()
+		if r == errAborted {
+			return
+		}
+		ev := event{kind: finished}
+		if r != nil {
+			ev = event{kind: panicked, value: r}
+		}
+		select {
+		case t.exec.events <- ev:
+		case <-t.exec.abort:
+		}
+	}()
+
+	select {
+	case <-t.resume:
+	case <-t.exec.abort:
+		panic(errAborted)
+	}
+	fn()
+}
@@ -0,0 +1,208 @@
+package interleave
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Правильный спин-лок на CompareAndSwap
+type casLock struct {
+	state Bool
+}
+
+func (l *casLock) Lock() {
+	for !l.state.CompareAndSwap(false, true) {
+	}
+}
+
+func (l *casLock) Unlock() {
+	l.state.Store(false)
+}
+
+// Сломанный: проверка и установка - две отдельные операции
+type testThenSetLock struct {
+	state Bool
+}
+
+func (l *testThenSetLock) Lock() {
+	for l.state.Load() {
+	}
+	l.state.Store(true)
+}
+
+func (l *testThenSetLock) Unlock() {
+	l.state.Store(false)
+}
+
+type locker interface {
+	Lock()
+	Unlock()
+}
+
+// Потоки по очереди заходят в критическую секцию, два внутри - паника
+func mutualExclusion(threads int, newLock func() locker) Setup {
+	return func() ([]func(), func() error) {
+		mu := newLock()
+		var inside Int32
+		var counter int
+
+		fns := make([]func(), threads)
+		for i := range fns {
+			fns[i] = func() {
+				mu.Lock()
+				if inside.Add(1) != 1 {
+					panic("two threads in critical section")
+				}
+				counter++
+				inside.Add(-1)
+				mu.Unlock()
+			}
+		}
+		check := func() error {
+			if counter != threads {
+				return errors.New("lost update")
+			}
+			return nil
+		}
+		return fns, check
+	}
+}
+
+func TestExplore_CorrectLock(t *testing.T) {
+	res := Explore(Config{Depth: 8}, mutualExclusion(2, func() locker { return &casLock{} }))
+	if res.Failure != nil {
+		t.Fatal(res.Failure)
+	}
+	if !res.Complete || res.Runs < 2 {
+		t.Errorf("Expected complete exploration of several schedules, got %+v", res)
+	}
+}
+
+func TestExplore_FindsBrokenLock(t *testing.T) {
+	setup := mutualExclusion(2, func() locker { return &testThenSetLock{} })
+	res := Explore(Config{Depth: 8}, setup)
+	if res.Failure == nil {
+		t.Fatalf("Explorer should find the test-then-set race in %d runs", res.Runs)
+	}
+	if !strings.Contains(res.Failure.Error(), "two threads in critical section") {
+		t.Errorf("Unexpected failure:\n%v", res.Failure)
+	}
+
+	// Найденное расписание воспроизводится
+	again := Replay(Config{}, res.Failure.Schedule, setup)
+	if again == nil || again.Err.Error() != res.Failure.Err.Error() {
+		t.Errorf("Replay should reproduce the failure, got %v", again)
+	}
+}
+
+func TestExplore_RandomFindsBrokenLock(t *testing.T) {
+	res := Explore(Config{Mode: Random, Runs: 1000, Seed: 1},
+		mutualExclusion(3, func() locker { return &testThenSetLock{} }))
+	if res.Failure == nil {
+		t.Fatalf("Random exploration should find the race in %d runs", res.Runs)
+	}
+	t.Logf("found in run %d:\n%v", res.Failure.Run, res.Failure)
+}
+
+/*
+TryLock ticket lock в том виде, в каком он был до исправления: между
+проверкой и Add билет может забрать другой поток, и тогда TryLock
+возвращает false, но билет остается за ним - очередь встает навсегда
+*/
+type oldTicketLock struct {
+	owner, next Int64
+}
+
+func (l *oldTicketLock) Lock() {
+	ticket := l.next.Add(1) - 1
+	for l.owner.Load() != ticket {
+	}
+}
+
+func (l *oldTicketLock) TryLock() bool {
+	if l.next.Load() != l.owner.Load() {
+		return false
+	}
+	ticket := l.next.Add(1) - 1
+	return l.owner.Load() == ticket
+}
+
+func (l *oldTicketLock) Unlock() {
+	l.owner.Add(1)
+}
+
+func TestExplore_FindsTicketTryLockLivelock(t *testing.T) {
+	setup := func() ([]func(), func() error) {
+		mu := &oldTicketLock{}
+		thread := func() {
+			if mu.TryLock() {
+				mu.Unlock()
+			}
+			mu.Lock()
+			mu.Unlock()
+		}
+		return []func(){thread, thread}, nil
+	}
+
+	res := Explore(Config{Depth: 12, MaxSteps: 500}, setup)
+	if res.Failure == nil {
+		t.Fatalf("Explorer should find the lost ticket in %d runs", res.Runs)
+	}
+	if !errors.Is(res.Failure.Err, ErrLivelock) {
+		t.Errorf("Expected livelock, got:\n%v", res.Failure)
+	}
+}
+
+func TestExplore_BlockedThread(t *testing.T) {
+	setup := func() ([]func(), func() error) {
+		block := make(chan struct{})
+		return []func(){func() { <-block }}, nil
+	}
+
+	res := Explore(Config{StepTimeout: 10 * time.Millisecond}, setup)
+	if res.Failure == nil || !strings.Contains(res.Failure.Err.Error(), "blocked outside instrumented operations") {
+		t.Errorf("Expected blocked thread failure, got %v", res.Failure)
+	}
+}
+
+func TestExplore_CheckFailure(t *testing.T) {
+	setup := func() ([]func(), func() error) {
+		var x Int64
+		inc := func() {
+			// неатомарный инкремент: Load и Store по отдельности
+			x.Store(x.Load() + 1)
+		}
+		check := func() error {
+			if x.Load() != 2 {
+				return errors.New("lost increment")
+			}
+			return nil
+		}
+		return []func(){inc, inc}, check
+	}
+
+	res := Explore(Config{}, setup)
+	if res.Failure == nil || res.Failure.Err.Error() != "lost increment" {
+		t.Fatalf("Expected lost increment, got %v", res.Failure)
+	}
+}
+
+func TestRoundRobin(t *testing.T) {
+	cases := []struct {
+		last    int
+		options []int
+		want    int
+	}{
+		{0, []int{0, 1, 2}, 1},
+		{2, []int{2, 0, 1}, 0},
+		{1, []int{0, 2}, 2},
+		{-1, []int{0, 1}, 0},
+	}
+	for _, c := range cases {
+		if got := roundRobin(c.last, c.options); got != c.want {
+			t.Errorf("roundRobin(%d, %v): expected %d, got %d", c.last, c.options, c.want, got)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package linearizability
+
+import "sort"
+
+/*
+Model - последовательная спецификация объекта. Step применяет операцию
+к состоянию: false - в этом состоянии операция не могла вернуть такой
+результат. Состояния должны быть сравнимыми (годятся как ключ map),
+по ним checker отсекает уже проверенные ветки
+*/
+type Model struct {
+	Init func() any
+	Step func(state any, op Operation) (ok bool, next any)
+}
+
+/*
+Check проверяет, что history линеаризуема относительно model: операции
+можно выстроить в последовательность, которая не противоречит реальному
+времени (закончившаяся раньше начала другой идет раньше нее) и в которой
+каждая операция возвращает то же, что в model.
+
+Алгоритм Wing-Gong с мемоизацией из статьи Lowe: перебираем, какую из
+еще не линеаризованных операций поставить следующей, и запоминаем пары
+(множество линеаризованных операций, состояние), которые уже не привели к успеху
+*/
+func Check(model Model, history []Operation) bool {
+	if len(history) == 0 {
+		return true
+	}
+
+	head := buildEntries(history)
+	linearized := newBitset(len(history))
+	seen := make(map[cacheKey]struct{})
+	state := model.Init()
+
+	type frame struct {
+		call  *entry
+		state any
+	}
+	var stack []frame
+
+	e := head.next
+	for head.next != nil {
+		if e.isCall {
+			ok, next := model.Step(state, history[e.id])
+			if ok {
+				linearized.set(e.id)
+				key := cacheKey{bits: linearized.key(), state: next}
+				if _, dup := seen[key]; !dup {
+					seen[key] = struct{}{}
+					stack = append(stack, frame{call: e, state: state})
+					state = next
+					e.lift()
+					e = head.next
+					continue
+				}
+				linearized.clear(e.id)
+			}
+			e = e.next
+			continue
+		}
+
+		// дошли до возврата операции, которую так и не смогли поставить: откат
+		if len(stack) == 0 {
+			return false
+		}
+		top := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		state = top.state
+		linearized.clear(top.call.id)
+		top.call.unlift()
+		e = top.call.next
+	}
+	return true
+}
+
+type cacheKey struct {
+	bits  string
+	state any
+}
+
+/*
+entry - вызов или возврат операции в общем списке событий, упорядоченном
+по времени. У вызова match указывает на его возврат. lift вынимает из списка
+обе записи линеаризованной операции, unlift возвращает их на место
+*/
+type entry struct {
+	id         int
+	isCall     bool
+	time       int64
+	match      *entry
+	prev, next *entry
+}
+
+func buildEntries(history []Operation) *entry {
+	events := make([]*entry, 0, 2*len(history))
+	for id, op := range history {
+		ret := &entry{id: id, time: op.Return}
+		call := &entry{id: id, isCall: true, time: op.Call, match: ret}
+		events = append(events, call, ret)
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].time < events[j].time
+	})
+
+	head := &entry{id: -1}
+	prev := head
+	for _, e := range events {
+		e.prev = prev
+		prev.next = e
+		prev = e
+	}
+	return head
+}
+
+func (e *entry) lift() {
+	e.prev.next = e.next
+	e.next.prev = e.prev
+	ret := e.match
+	ret.prev.next = ret.next
+	if ret.next != nil {
+		ret.next.prev = ret.prev
+	}
+}
+
+func (e *entry) unlift() {
+	ret := e.match
+	ret.prev.next = ret
+	if ret.next != nil {
+		ret.next.prev = ret
+	}
+	e.prev.next = e
+	e.next.prev = e
+}
+
+type bitset []uint64
+
+func newBitset(n int) bitset {
+	return make(bitset, (n+63)/64)
+}
+
+func (b bitset) set(i int) {
+	b[i/64] |= 1 << (i % 64)
+}
+
+func (b bitset) clear(i int) {
+	b[i/64] &^= 1 << (i % 64)
+}
+
+// Копия содержимого в виде строки, чтобы использовать как ключ map
+func (b bitset) key() string {
+	buf := make([]byte, 0, len(b)*8)
+	for _, word := range b {
+		for i := 0; i < 8; i++ {
+			buf = append(buf, byte(word>>(8*i)))
+		}
+	}
+	return string(buf)
+}
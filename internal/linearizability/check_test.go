@@ -0,0 +1,61 @@
+package linearizability
+
+import "testing"
+
+// op - операция с явными временами для рукописных историй
+func op(client int, input, output any, call, ret int64) Operation {
+	return Operation{Client: client, Input: input, Output: output, Call: call, Return: ret}
+}
+
+func TestCheck_Empty(t *testing.T) {
+	if !Check(MutexModel(), nil) {
+		t.Error("Empty history is linearizable")
+	}
+}
+
+// Длинная история с пересекающимися операциями: мемоизация не дает перебору взорваться
+func longHistory(rounds int) []Operation {
+	var history []Operation
+	var clock int64
+	for i := 0; i < rounds; i++ {
+		owner := i % 4
+		history = append(history, op(owner, Lock, nil, clock+1, clock+2))
+		// неудачные TryLock остальных клиентов пересекаются между собой внутри секции
+		n := int64(0)
+		for other := 0; other < 4; other++ {
+			if other != owner {
+				history = append(history, op(other, TryLock, false, clock+3+n, clock+6+n))
+				n++
+			}
+		}
+		history = append(history, op(owner, Unlock, nil, clock+9, clock+10))
+		clock += 10
+	}
+	return history
+}
+
+func TestCheck_LongHistory(t *testing.T) {
+	history := longHistory(500)
+	if !Check(MutexModel(), history) {
+		t.Error("Long history should be linearizable")
+	}
+
+	// последний Unlock делает чужой клиент: ошибку видно только в самом конце
+	history[len(history)-1].Client = (history[len(history)-1].Client + 1) % 4
+	if Check(MutexModel(), history) {
+		t.Error("History with a foreign Unlock at the end should not be linearizable")
+	}
+}
+
+// Первый пришедший в голову порядок не подходит, нужен откат
+func TestCheck_Backtracking(t *testing.T) {
+	history := []Operation{
+		op(0, Lock, nil, 1, 10),
+		op(1, Lock, nil, 2, 5),
+		op(1, Unlock, nil, 6, 7),
+		op(0, Unlock, nil, 11, 12),
+	}
+	if !Check(MutexModel(), history) {
+		t.Error("Lock of client 0 can take effect after Unlock of client 1")
+	}
+}
@@ -0,0 +1,103 @@
+package linearizability
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+/*
+Operation - одна операция истории: клиент Client вызвал операцию Input
+в момент Call и получил Output в момент Return. Время логическое,
+общие часы Recorder, так что Return < Call другой операции значит,
+что первая закончилась раньше, чем началась вторая
+*/
+type Operation struct {
+	Client int
+	Input  any
+	Output any
+	Call   int64
+	Return int64
+}
+
+func (op Operation) String() string {
+	return fmt.Sprintf("[%d, %d] client %d: %v -> %v", op.Call, op.Return, op.Client, op.Input, op.Output)
+}
+
+/*
+Recorder собирает историю стресс-теста. Каждая горутина пишет в своего
+Client, без общих мьютексов, чтобы запись сама не упорядочивала операции
+сильнее, чем тестируемый код. Общие только часы - один атомарный счетчик
+*/
+type Recorder struct {
+	clock atomic.Int64
+
+	mu      sync.Mutex
+	clients []*Client
+}
+
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+// Client заводит журнал для одной горутины
+func (r *Recorder) Client() *Client {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c := &Client{id: len(r.clients), clock: &r.clock}
+	r.clients = append(r.clients, c)
+	return c
+}
+
+/*
+History возвращает все завершенные операции, упорядоченные по Call.
+Вызывать после того, как все горутины закончили запись. Операция
+без Return (горутина упала посередине) в историю не попадает
+*/
+func (r *Recorder) History() []Operation {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var history []Operation
+	for _, c := range r.clients {
+		for _, op := range c.ops {
+			if op.Return != 0 {
+				history = append(history, op)
+			}
+		}
+	}
+	sort.Slice(history, func(i, j int) bool {
+		return history[i].Call < history[j].Call
+	})
+	return history
+}
+
+// Client - журнал одной горутины, операции в нем идут строго по очереди
+type Client struct {
+	id      int
+	clock   *atomic.Int64
+	ops     []Operation
+	pending bool
+}
+
+// Invoke отмечает вызов операции, звать прямо перед ней
+func (c *Client) Invoke(input any) {
+	if c.pending {
+		panic("linearizability: Invoke before Return of the previous operation")
+	}
+	c.pending = true
+	c.ops = append(c.ops, Operation{Client: c.id, Input: input, Call: c.clock.Add(1)})
+}
+
+// Return отмечает завершение операции с результатом output, звать сразу после нее
+func (c *Client) Return(output any) {
+	if !c.pending {
+		panic("linearizability: Return without Invoke")
+	}
+	c.pending = false
+	op := &c.ops[len(c.ops)-1]
+	op.Output = output
+	op.Return = c.clock.Add(1)
+}
@@ -0,0 +1,98 @@
+package linearizability
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestRecorder_History(t *testing.T) {
+	r := NewRecorder()
+	a, b := r.Client(), r.Client()
+
+	a.Invoke(Lock)
+	b.Invoke(TryLock)
+	b.Return(false)
+	a.Return(nil)
+	a.Invoke(Unlock)
+	a.Return(nil)
+
+	history := r.History()
+	if len(history) != 3 {
+		t.Fatalf("Expected 3 operations, got %v", history)
+	}
+	if history[0].Client != 0 || history[0].Input != Lock || history[0].Call != 1 || history[0].Return != 4 {
+		t.Errorf("Unexpected first operation: %v", history[0])
+	}
+	if history[1].Client != 1 || history[1].Output != false || history[1].Call != 2 || history[1].Return != 3 {
+		t.Errorf("Unexpected second operation: %v", history[1])
+	}
+	if history[2].Input != Unlock || history[2].Call != 5 {
+		t.Errorf("Unexpected third operation: %v", history[2])
+	}
+}
+
+func TestRecorder_PendingOperationIsDropped(t *testing.T) {
+	r := NewRecorder()
+	c := r.Client()
+	c.Invoke(Lock)
+	c.Return(nil)
+	c.Invoke(Unlock)
+
+	if history := r.History(); len(history) != 1 {
+		t.Errorf("Operation without Return should not be in history, got %v", history)
+	}
+}
+
+func TestRecorder_Concurrent(t *testing.T) {
+	r := NewRecorder()
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c := r.Client()
+			for i := 0; i < 100; i++ {
+				c.Invoke(Err)
+				c.Return(nil)
+			}
+		}()
+	}
+	wg.Wait()
+
+	history := r.History()
+	if len(history) != 800 {
+		t.Fatalf("Expected 800 operations, got %d", len(history))
+	}
+	seen := make(map[int64]bool)
+	for i, op := range history {
+		if op.Call >= op.Return {
+			t.Fatalf("Call should come before Return: %v", op)
+		}
+		if i > 0 && history[i-1].Call >= op.Call {
+			t.Fatalf("History is not sorted by Call at %d", i)
+		}
+		if seen[op.Call] || seen[op.Return] {
+			t.Fatalf("Timestamps should be unique: %v", op)
+		}
+		seen[op.Call], seen[op.Return] = true, true
+	}
+}
+
+func TestClient_Misuse(t *testing.T) {
+	c := NewRecorder().Client()
+
+	expectPanic(t, "Return without Invoke", func() { c.Return(nil) })
+
+	c.Invoke(Lock)
+	expectPanic(t, "Invoke before Return", func() { c.Invoke(Unlock) })
+}
+
+func expectPanic(t *testing.T, name string, f func()) {
+	t.Helper()
+	defer func() {
+		if recover() == nil {
+			t.Errorf("%s should panic", name)
+		}
+	}()
+	f()
+}
@@ -0,0 +1,101 @@
+package linearizability
+
+import "fmt"
+
+// MutexOp - Input операции над мьютексом, Output есть только у TryLock (bool)
+type MutexOp int
+
+const (
+	Lock MutexOp = iota
+	Unlock
+	TryLock
+)
+
+func (op MutexOp) String() string {
+	switch op {
+	case Lock:
+		return "Lock"
+	case Unlock:
+		return "Unlock"
+	case TryLock:
+		return "TryLock"
+	default:
+		return fmt.Sprintf("MutexOp(%d)", int(op))
+	}
+}
+
+const free = -1
+
+/*
+MutexModel - последовательный мьютекс. Состояние - клиент-владелец или free.
+Lock и успешный TryLock возможны только на свободном мьютексе, Unlock -
+только владельцем, неудачный TryLock - только когда мьютекс кем-то занят
+*/
+func MutexModel() Model {
+	return Model{
+		Init: func() any { return free },
+		Step: func(state any, op Operation) (bool, any) {
+			owner := state.(int)
+			switch op.Input.(MutexOp) {
+			case Lock:
+				return owner == free, op.Client
+			case Unlock:
+				return owner == op.Client, free
+			case TryLock:
+				if op.Output.(bool) {
+					return owner == free, op.Client
+				}
+				return owner != free, owner
+			}
+			return false, state
+		},
+	}
+}
+
+// ContextOp - Input операции над контекстом, Output есть только у Err (error)
+type ContextOp int
+
+const (
+	// Cancel - отмена самого контекста или любого его предка
+	Cancel ContextOp = iota
+	Err
+)
+
+func (op ContextOp) String() string {
+	switch op {
+	case Cancel:
+		return "Cancel"
+	case Err:
+		return "Err"
+	default:
+		return fmt.Sprintf("ContextOp(%d)", int(op))
+	}
+}
+
+/*
+ContextModel - последовательный отменяемый контекст, отмена выставляет ошибку
+cancelErr. Состояние - отменен ли контекст. Err возвращает nil до первой отмены
+и cancelErr после нее, повторная отмена ничего не меняет.
+
+Отмена предка доходит до детей не атомарно: родитель уже отменен, а ребенок еще нет.
+Поэтому историю проверяют для каждого контекста отдельно, записывая в нее
+и отмены его предков: к моменту возврата их cancel ребенок обязан быть отменен
+*/
+func ContextModel(cancelErr error) Model {
+	return Model{
+		Init: func() any { return false },
+		Step: func(state any, op Operation) (bool, any) {
+			canceled := state.(bool)
+			switch op.Input.(ContextOp) {
+			case Cancel:
+				return true, true
+			case Err:
+				if canceled {
+					return op.Output == cancelErr, true
+				}
+				return op.Output == nil, false
+			}
+			return false, state
+		},
+	}
+}
@@ -0,0 +1,174 @@
+package linearizability
+
+import (
+	"context"
+	"testing"
+)
+
+func TestMutexModel(t *testing.T) {
+	cases := []struct {
+		name    string
+		history []Operation
+		want    bool
+	}{
+		{
+			name: "sequential",
+			history: []Operation{
+				op(0, Lock, nil, 1, 2),
+				op(0, Unlock, nil, 3, 4),
+				op(1, Lock, nil, 5, 6),
+				op(1, Unlock, nil, 7, 8),
+			},
+			want: true,
+		},
+		{
+			// Lock клиента 1 ждал, пока клиент 0 отпустит мьютекс
+			name: "overlapping lock waits for unlock",
+			history: []Operation{
+				op(0, Lock, nil, 1, 2),
+				op(1, Lock, nil, 3, 8),
+				op(0, Unlock, nil, 4, 5),
+				op(1, Unlock, nil, 9, 10),
+			},
+			want: true,
+		},
+		{
+			name: "two owners at once",
+			history: []Operation{
+				op(0, Lock, nil, 1, 2),
+				op(1, Lock, nil, 3, 4),
+				op(0, Unlock, nil, 5, 6),
+				op(1, Unlock, nil, 7, 8),
+			},
+			want: false,
+		},
+		{
+			// TryLock пересекается с Unlock: мог увидеть мьютекс еще занятым
+			name: "failed trylock concurrent with unlock",
+			history: []Operation{
+				op(0, Lock, nil, 1, 2),
+				op(0, Unlock, nil, 3, 6),
+				op(1, TryLock, false, 4, 5),
+			},
+			want: true,
+		},
+		{
+			name: "failed trylock on free mutex",
+			history: []Operation{
+				op(0, Lock, nil, 1, 2),
+				op(0, Unlock, nil, 3, 4),
+				op(1, TryLock, false, 5, 6),
+			},
+			want: false,
+		},
+		{
+			name: "successful trylock on held mutex",
+			history: []Operation{
+				op(0, Lock, nil, 1, 2),
+				op(1, TryLock, true, 3, 4),
+				op(0, Unlock, nil, 5, 6),
+			},
+			want: false,
+		},
+		{
+			name: "unlock by another client",
+			history: []Operation{
+				op(0, Lock, nil, 1, 2),
+				op(1, Unlock, nil, 3, 4),
+			},
+			want: false,
+		},
+		{
+			// только один из двух пересекающихся TryLock может вернуть true
+			name: "concurrent trylocks",
+			history: []Operation{
+				op(0, TryLock, true, 1, 4),
+				op(1, TryLock, false, 2, 3),
+				op(0, Unlock, nil, 5, 6),
+			},
+			want: true,
+		},
+	}
+
+	for _, c := range cases {
+		if got := Check(MutexModel(), c.history); got != c.want {
+			t.Errorf("%s: expected %v, got %v", c.name, c.want, got)
+		}
+	}
+}
+
+func TestContextModel(t *testing.T) {
+	cases := []struct {
+		name    string
+		history []Operation
+		want    bool
+	}{
+		{
+			name: "err after cancel",
+			history: []Operation{
+				op(0, Err, nil, 1, 2),
+				op(1, Cancel, nil, 3, 4),
+				op(0, Err, context.Canceled, 5, 6),
+			},
+			want: true,
+		},
+		{
+			// Err пересекается с cancel: годится любой ответ
+			name: "err concurrent with cancel",
+			history: []Operation{
+				op(1, Cancel, nil, 1, 6),
+				op(0, Err, nil, 2, 3),
+				op(2, Err, context.Canceled, 4, 5),
+			},
+			want: true,
+		},
+		{
+			// но увидев отмену, контекст уже не может стать неотмененным
+			name: "err goes back to nil",
+			history: []Operation{
+				op(1, Cancel, nil, 1, 6),
+				op(0, Err, context.Canceled, 2, 3),
+				op(2, Err, nil, 4, 5),
+			},
+			want: false,
+		},
+		{
+			name: "nil err after cancel returned",
+			history: []Operation{
+				op(1, Cancel, nil, 1, 2),
+				op(0, Err, nil, 3, 4),
+			},
+			want: false,
+		},
+		{
+			name: "canceled err without cancel",
+			history: []Operation{
+				op(0, Err, context.Canceled, 1, 2),
+			},
+			want: false,
+		},
+		{
+			name: "wrong error",
+			history: []Operation{
+				op(1, Cancel, nil, 1, 2),
+				op(0, Err, context.DeadlineExceeded, 3, 4),
+			},
+			want: false,
+		},
+		{
+			name: "repeated cancel",
+			history: []Operation{
+				op(0, Cancel, nil, 1, 4),
+				op(1, Cancel, nil, 2, 3),
+				op(2, Err, context.Canceled, 5, 6),
+			},
+			want: true,
+		},
+	}
+
+	for _, c := range cases {
+		if got := Check(ContextModel(context.Canceled), c.history); got != c.want {
+			t.Errorf("%s: expected %v, got %v", c.name, c.want, got)
+		}
+	}
+}
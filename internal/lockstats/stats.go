@@ -0,0 +1,124 @@
+package lockstats
+
+import (
+	"math/bits"
+	"sync/atomic"
+	"time"
+)
+
+// Buckets - число корзин гистограммы ожидания, последняя собирает все, что длиннее
+const Buckets = 40
+
+/*
+Stats - снимок счетчиков одного мьютекса. Счетчики снимаются по одному,
+поэтому при идущих захватах поля могут немного не сходиться между собой
+*/
+type Stats struct {
+	// успешные захваты: Lock, TryLock и прочие
+	Acquisitions uint64
+	// захваты, которым пришлось ждать хотя бы одну неудачную попытку
+	Contended uint64
+	// неудачные попытки: CompareAndSwap у CAS lock, проверки билета у ticket lock
+	FailedAttempts uint64
+	// вызовы runtime.Gosched во время ожидания
+	Yields uint64
+	// суммарное время ожидания захвата
+	WaitTotal time.Duration
+	// самое долгое удержание мьютекса
+	MaxHold time.Duration
+	// WaitHistogram[i] - число захватов с ожиданием в пределах BucketBound(i)
+	WaitHistogram [Buckets]uint64
+}
+
+/*
+BucketBound - верхняя граница корзины i (не включительно): корзина 0 - ожидания
+не было, корзина i - от 2^(i-1) до 2^i наносекунд
+*/
+func BucketBound(i int) time.Duration {
+	return time.Duration(1) << i
+}
+
+/*
+Recorder копит статистику мьютекса. Мьютексы держат *Recorder и
+вызывают его только если он не nil, так что выключенная статистика
+стоит одну проверку указателя
+*/
+type Recorder struct {
+	acquisitions   atomic.Uint64
+	contended      atomic.Uint64
+	failedAttempts atomic.Uint64
+	yields         atomic.Uint64
+	waitTotal      atomic.Int64
+	maxHold        atomic.Int64
+	waitHistogram  [Buckets]atomic.Uint64
+
+	// момент захвата в наносекундах от start, 0 - сейчас никто не держит через Acquired
+	holdStart atomic.Int64
+	start     time.Time
+}
+
+func NewRecorder() *Recorder {
+	return &Recorder{start: time.Now()}
+}
+
+// Acquired вызывается владельцем сразу после захвата
+func (r *Recorder) Acquired(wait time.Duration, failedAttempts, yields uint64) {
+	r.acquisitions.Add(1)
+	if failedAttempts > 0 {
+		r.contended.Add(1)
+		r.failedAttempts.Add(failedAttempts)
+	}
+	if yields > 0 {
+		r.yields.Add(yields)
+	}
+	r.waitTotal.Add(int64(wait))
+	r.waitHistogram[bucket(wait)].Add(1)
+
+	// +1, чтобы захват в самый момент создания не совпал с "никто не держит"
+	r.holdStart.Store(int64(time.Since(r.start)) + 1)
+}
+
+// Failed учитывает неудачные попытки, после которых мьютекс так и не захвачен
+func (r *Recorder) Failed(failedAttempts, yields uint64) {
+	r.failedAttempts.Add(failedAttempts)
+	r.yields.Add(yields)
+}
+
+// Released вызывается владельцем перед освобождением
+func (r *Recorder) Released() {
+	start := r.holdStart.Swap(0)
+	if start == 0 {
+		// мьютекс захвачен в обход Acquired, время удержания неизвестно
+		return
+	}
+
+	hold := int64(time.Since(r.start)) + 1 - start
+	for {
+		max := r.maxHold.Load()
+		if hold <= max || r.maxHold.CompareAndSwap(max, hold) {
+			return
+		}
+	}
+}
+
+func (r *Recorder) Snapshot() Stats {
+	s := Stats{
+		Acquisitions:   r.acquisitions.Load(),
+		Contended:      r.contended.Load(),
+		FailedAttempts: r.failedAttempts.Load(),
+		Yields:         r.yields.Load(),
+		WaitTotal:      time.Duration(r.waitTotal.Load()),
+		MaxHold:        time.Duration(r.maxHold.Load()),
+	}
+	for i := range s.WaitHistogram {
+		s.WaitHistogram[i] = r.waitHistogram[i].Load()
+	}
+	return s
+}
+
+func bucket(wait time.Duration) int {
+	if wait <= 0 {
+		return 0
+	}
+	return min(bits.Len64(uint64(wait)), Buckets-1)
+}
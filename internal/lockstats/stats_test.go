@@ -0,0 +1,77 @@
+package lockstats
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBucket(t *testing.T) {
+	cases := []struct {
+		wait time.Duration
+		want int
+	}{
+		{0, 0},
+		{-time.Second, 0},
+		{1, 1},
+		{2, 2},
+		{3, 2},
+		{1024, 11},
+		{time.Duration(1) << 62, Buckets - 1},
+	}
+	for _, c := range cases {
+		if got := bucket(c.wait); got != c.want {
+			t.Errorf("bucket(%d): expected %d, got %d", c.wait, c.want, got)
+		}
+		if c.wait > 0 && c.want < Buckets-1 && c.wait >= BucketBound(c.want) {
+			t.Errorf("wait %d should be below BucketBound(%d) = %d", c.wait, c.want, BucketBound(c.want))
+		}
+	}
+}
+
+func TestRecorder_Snapshot(t *testing.T) {
+	r := NewRecorder()
+	r.Acquired(0, 0, 0)
+	r.Released()
+	r.Acquired(100*time.Nanosecond, 5, 2)
+	r.Released()
+	r.Failed(7, 1)
+
+	s := r.Snapshot()
+	if s.Acquisitions != 2 {
+		t.Errorf("Expected 2 acquisitions, got %d", s.Acquisitions)
+	}
+	if s.Contended != 1 {
+		t.Errorf("Expected 1 contended acquisition, got %d", s.Contended)
+	}
+	if s.FailedAttempts != 12 || s.Yields != 3 {
+		t.Errorf("Expected 12 failed attempts and 3 yields, got %d and %d", s.FailedAttempts, s.Yields)
+	}
+	if s.WaitTotal != 100*time.Nanosecond {
+		t.Errorf("Expected total wait 100ns, got %v", s.WaitTotal)
+	}
+	if s.WaitHistogram[0] != 1 || s.WaitHistogram[bucket(100)] != 1 {
+		t.Errorf("Unexpected histogram %v", s.WaitHistogram)
+	}
+}
+
+func TestRecorder_MaxHold(t *testing.T) {
+	r := NewRecorder()
+	r.Acquired(0, 0, 0)
+	time.Sleep(5 * time.Millisecond)
+	r.Released()
+
+	r.Acquired(0, 0, 0)
+	r.Released()
+
+	if hold := r.Snapshot().MaxHold; hold < 5*time.Millisecond {
+		t.Errorf("Expected max hold at least 5ms, got %v", hold)
+	}
+}
+
+func TestRecorder_ReleasedWithoutAcquired(t *testing.T) {
+	r := NewRecorder()
+	r.Released()
+	if hold := r.Snapshot().MaxHold; hold != 0 {
+		t.Errorf("Release without Acquired should not record hold time, got %v", hold)
+	}
+}
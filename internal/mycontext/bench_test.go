@@ -0,0 +1,124 @@
+package mycontext
+
+import (
+	"my_concurency/internal/mylocker"
+	"my_concurency/internal/mymutexcas"
+	"my_concurency/internal/mymutexclh"
+	"my_concurency/internal/mymutexhybrid"
+	"my_concurency/internal/mymutexmcs"
+	"my_concurency/internal/mymutextic"
+	"testing"
+)
+
+/*
+Те же реализации мьютекса, что в бенчмарках mylocker, но внутри дерева
+контекстов. Запуск и сравнение:
+
+	go test -run '^$' -bench . -count 10 -cpu 1,4,8 ./internal/mycontext/ > new.txt
+	benchstat old.txt new.txt
+*/
+
+var benchLockers = []struct {
+	name    string
+	factory mylocker.Factory
+}{
+	{"sync", mylocker.Sync},
+	{"mymutexcas", func() mylocker.Locker { return &mymutexcas.Mutex{} }},
+	{"mymutextic", func() mylocker.Locker { return &mymutextic.Mutex{} }},
+	{"mymutexmcs", func() mylocker.Locker { return &mymutexmcs.Mutex{} }},
+	{"mymutexclh", func() mylocker.Locker { return &mymutexclh.Mutex{} }},
+	{"mymutexhybrid", func() mylocker.Locker { return &mymutexhybrid.Mutex{} }},
+}
+
+func benchEachLocker(b *testing.B, bench func(b *testing.B, root Context)) {
+	for _, l := range benchLockers {
+		b.Run(l.name, func(b *testing.B) {
+			bench(b, Background(WithLocker(l.factory)))
+		})
+	}
+}
+
+// Создание и отмена одного дочернего контекста: регистрация в родителе и удаление из него
+func BenchmarkWithCancel(b *testing.B) {
+	benchEachLocker(b, func(b *testing.B, root Context) {
+		parent, cancel := WithCancel(root)
+		defer cancel()
+
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			_, childCancel := WithCancel(parent)
+			childCancel()
+		}
+	})
+}
+
+// То же из многих горутин: все бьются за мьютекс общего родителя
+func BenchmarkWithCancel_Parallel(b *testing.B) {
+	benchEachLocker(b, func(b *testing.B, root Context) {
+		parent, cancel := WithCancel(root)
+		defer cancel()
+
+		b.ResetTimer()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				_, childCancel := WithCancel(parent)
+				childCancel()
+			}
+		})
+	})
+}
+
+// Отмена родителя со 100 детьми
+func BenchmarkCancel_FanOut(b *testing.B) {
+	benchEachLocker(b, func(b *testing.B, root Context) {
+		for i := 0; i < b.N; i++ {
+			parent, cancel := WithCancel(root)
+			for j := 0; j < 100; j++ {
+				WithCancel(parent)
+			}
+			cancel()
+		}
+	})
+}
+
+// Err под параллельной нагрузкой: читает состояние под мьютексом
+func BenchmarkErr_Parallel(b *testing.B) {
+	benchEachLocker(b, func(b *testing.B, root Context) {
+		ctx, cancel := WithCancel(root)
+		defer cancel()
+
+		b.ResetTimer()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				if ctx.Err() != nil {
+					b.Error("Context should not be cancelled")
+				}
+			}
+		})
+	})
+}
+
+// Поиск значения через 10 уровней WithValue и WithCancel
+func BenchmarkValue_Deep(b *testing.B) {
+	benchEachLocker(b, func(b *testing.B, root Context) {
+		ctx := WithValue(root, testKey("root"), "v")
+		var cancels []func()
+		for i := 0; i < 10; i++ {
+			var cancel func()
+			ctx, cancel = WithCancel(WithValue(ctx, testKey("level"), i))
+			cancels = append(cancels, cancel)
+		}
+		defer func() {
+			for _, cancel := range cancels {
+				cancel()
+			}
+		}()
+
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if ctx.Value(testKey("root")) != "v" {
+				b.Fatal("Value should be found through the chain")
+			}
+		}
+	})
+}
@@ -1,210 +1,524 @@
 package mycontext
 
 import (
-	"errors"
+	"context"
+	"my_concurency/internal/mylocker"
+	"reflect"
 	"sync"
 	"time"
 )
 
+// Ошибки те же, что и в стандартной библиотеке, чтобы errors.Is работал с обоими видами контекстов
 var (
-	Canceled         = errors.New("context canceled")
-	DeadlineExceeded = errors.New("context deadline exceeded")
+	Canceled         = context.Canceled
+	DeadlineExceeded = context.DeadlineExceeded
+)
+
+/*
+Context - интерфейс контекста, совпадает с context.Context из стандартной
+библиотеки, поэтому родителем может быть любая реализация, а наши контексты
+можно передавать в net/http, database/sql и т.д.
+*/
+type Context interface {
+	Deadline() (deadline time.Time, ok bool)
+	Done() <-chan struct{}
+	Err() error
+	Value(key any) any
+}
+
+var (
+	_ context.Context = Context(nil)
+	_ Context         = context.Context(nil)
 )
 
 /*
 Это простая реализация контекста в учебных целях, здесь 100% есть ошибки.
-Будет работать и моими реализациями Мютекса
+Будет работать и моими реализациями Мютекса, какой именно мьютекс
+использовать задается через WithLocker при создании Background.
+Одна структура на все виды контекстов (Background, WithCancel, WithValue...)
 */
-type Context struct {
-	done   chan struct{}
-	parent *Context
-	timer  *time.Timer
-	mu     sync.Mutex
-	err    error
+type myContext struct {
+	done      chan struct{}
+	parent    Context
+	timer     *time.Timer
+	mu        mylocker.Locker
+	newLocker mylocker.Factory
+	err       error
+	// причина отмены, см. Cause
+	cause error
+	// дедлайн, свой или унаследованный от предков, нулевой если его нет
+	deadline time.Time
+	// причина, которая запишется при срабатывании таймера WithDeadlineCause
+	deadlineCause error
+	// контекст можно отменить (WithCancel, WithDeadline), только к таким регистрируются дети
+	cancelable bool
+	// зарегистрированные дети, отменяются сразу вместе с родителем
+	children map[*myContext]struct{}
+	// отписка от чужого родителя (FromStd), вызывается при отмене контекста
+	stopParent func() bool
+
+	// для AfterFunc: afterFunc запускается один раз при отмене, если stop не успел раньше
+	afterFunc func()
+	afterOnce sync.Once
+
+	// для WithValue
+	key, val any
+	// родитель WithoutCancel: от него берутся только значения, без отмены
+	detached Context
+}
+
+// Option настраивает корневой контекст, созданный через Background
+type Option func(*myContext)
+
+/*
+WithLocker задает фабрику мьютексов для всего дерева контекстов:
+дочерние контексты берут ее у родителя, так что достаточно
+указать ее один раз в Background. По умолчанию используется sync.Mutex
+*/
+func WithLocker(factory mylocker.Factory) Option {
+	return func(mc *myContext) {
+		if factory != nil {
+			mc.newLocker = factory
+		}
+	}
+}
+
+/*
+Создает пустой контекст, мьютекс берется из фабрики from
+(или sync.Mutex, если from == nil или это чужой контекст)
+*/
+func newContext(from Context) *myContext {
+	factory := mylocker.Factory(mylocker.Sync)
+	if mc, ok := from.(*myContext); ok && mc.newLocker != nil {
+		factory = mc.newLocker
+	}
+
+	return &myContext{
+		done:      make(chan struct{}),
+		mu:        factory(),
+		newLocker: factory,
+	}
 }
 
-// Общая функция для безопасной отмены контекста
-func (mc *Context) safeCancel(err error) {
+/*
+Общая функция для безопасной отмены контекста.
+Если cause не задан, причиной считается сама ошибка, как в стандартной библиотеке.
+Вместе с контекстом сразу отменяются все его дети, а сам он
+удаляется из родителя, если отмена пришла не от родителя (removeFromParent)
+*/
+func (mc *myContext) safeCancel(removeFromParent bool, err, cause error) {
+	if cause == nil {
+		cause = err
+	}
 
 	mc.mu.Lock()
-	defer mc.mu.Unlock()
 	select {
 	case <-mc.done:
+		mc.mu.Unlock()
 		return
 	default:
-		mc.err = err
-		close(mc.done)
 	}
-}
 
-// В оригинале принимает интерфейс, но я пока одной структурой обошелся
-func WithCancel(parent *Context) (*Context, func()) {
-	child := Context{
-		done:   make(chan struct{}),
-		parent: parent,
+	if mc.timer != nil {
+		mc.timer.Stop()
 	}
+	mc.err = err
+	mc.cause = cause
+	close(mc.done)
 
-	cancel := func() {
-		child.safeCancel(Canceled)
+	children := mc.children
+	mc.children = nil
+	mc.mu.Unlock()
+
+	if mc.afterFunc != nil {
+		mc.afterOnce.Do(func() {
+			go mc.afterFunc()
+		})
 	}
-	return &child, cancel
-}
 
-func WithoutCancel(parent *Context) *Context {
-	child := Context{
-		done: make(chan struct{}),
+	/*
+		детей отменяем уже без своего мьютекса, чтобы не держать
+		сразу два лока. Новый ребенок не потеряется: propagateCancel
+		под мьютексом увидит закрытый done и отменит его сам
+	*/
+	for child := range children {
+		child.safeCancel(false, err, cause)
 	}
 
-	return &child
+	if removeFromParent {
+		if parent, ok := mc.cancelParent().(*myContext); ok && parent.cancelable {
+			parent.removeChild(mc)
+		}
+		if mc.stopParent != nil {
+			mc.stopParent()
+		}
+	}
 }
 
-func WithDeadline(parent *Context, ddl time.Time) (*Context, func()) {
-	now := time.Now()
-	if now.After(ddl) {
-		child := &Context{
-			done: make(chan struct{}),
-			err:  DeadlineExceeded,
+/*
+Ближайший предок, от которого может прийти отмена.
+Контексты WithValue пропускаем: своей отмены у них нет, она берется у родителя
+*/
+func (mc *myContext) cancelParent() Context {
+	parent := mc.parent
+	for {
+		p, ok := parent.(*myContext)
+		if !ok || p.key == nil {
+			return parent
 		}
-		close(child.done)
-		return child, func() {}
+		parent = p.parent
 	}
+}
 
-	child := &Context{
-		done:   make(chan struct{}),
-		parent: parent,
-		timer:  time.NewTimer(time.Until(ddl)),
+// Регистрирует child у родителя, если родитель уже отменен - отменяет child сразу
+func (mc *myContext) propagateCancel(child *myContext) {
+	mc.mu.Lock()
+	select {
+	case <-mc.done:
+		err, cause := mc.err, mc.cause
+		mc.mu.Unlock()
+		child.safeCancel(false, err, cause)
+		return
+	default:
 	}
 
-	cancel := func() {
-		child.mu.Lock()
-		defer child.mu.Unlock()
-		select {
-		case <-child.done:
-		default:
-			if child.timer != nil {
-				child.timer.Stop()
-			}
-			child.err = Canceled
-			close(child.done)
+	if mc.children == nil {
+		mc.children = make(map[*myContext]struct{})
+	}
+	mc.children[child] = struct{}{}
+	mc.mu.Unlock()
+}
+
+func (mc *myContext) removeChild(child *myContext) {
+	mc.mu.Lock()
+	delete(mc.children, child)
+	mc.mu.Unlock()
+}
+
+/*
+Подписывает контекст на отмену ближайшего предка. Быстрый путь - предок наш:
+регистрируемся у него в children. Для чужой реализации так не получится,
+поэтому следим за ее Done из отдельной горутины
+*/
+func (mc *myContext) register() {
+	switch parent := mc.cancelParent().(type) {
+	case nil:
+	case *myContext:
+		if parent.cancelable {
+			parent.propagateCancel(mc)
 		}
+	default:
+		mc.watchParent(parent)
+	}
+}
+
+func (mc *myContext) watchParent(parent Context) {
+	done := parent.Done()
+	if done == nil {
+		// такой контекст никогда не отменяется
+		return
+	}
+
+	select {
+	case <-done:
+		mc.safeCancel(false, parent.Err(), Cause(parent))
+		return
+	default:
 	}
 
-	/*
-		Запускаем мониторинг таймера в отдельной горутине,
-		как минимум для асинхронной рабоыт таймера
-	*/
 	go func() {
 		select {
-		case <-child.timer.C:
-			child.mu.Lock()
-			select {
-			case <-child.done:
-			default:
-				child.err = DeadlineExceeded
-				close(child.done)
-			}
-			child.mu.Unlock()
-		case <-child.done:
+		case <-done:
+			mc.safeCancel(false, parent.Err(), Cause(parent))
+		case <-mc.done:
 		}
 	}()
-
-	return child, cancel
 }
 
-func WithTimeout(parent *Context, duration time.Duration) (*Context, func()) {
-	return WithDeadline(parent, time.Now().Add(duration))
+// Создает отменяемый дочерний контекст и подписывает его на отмену родителя
+func newCancelContext(parent Context) *myContext {
+	child := newContext(parent)
+	child.parent = parent
+	child.cancelable = true
+	if parent != nil {
+		child.deadline, _ = parent.Deadline()
+	}
+	child.register()
+	return child
 }
 
-func Background() *Context {
-	return &Context{
-		done: make(chan struct{}),
+/*
+AfterFunc запускает f в отдельной горутине, когда ctx будет отменен
+(вручную, по дедлайну или вместе с кем-то из предков). Если ctx уже
+отменен, f запускается сразу. stop отвязывает f от ctx и возвращает true,
+если f еще не была запущена, и false если f уже запущена или stop уже вызывали
+*/
+func AfterFunc(ctx Context, f func()) (stop func() bool) {
+	// внутри это обычный дочерний контекст, который при отмене запускает f
+	a := newContext(ctx)
+	a.parent = ctx
+	a.cancelable = true
+	if ctx != nil {
+		a.deadline, _ = ctx.Deadline()
+	}
+	a.afterFunc = f
+	a.register()
+
+	return func() bool {
+		stopped := false
+		a.afterOnce.Do(func() {
+			stopped = true
+		})
+		if stopped {
+			a.safeCancel(true, Canceled, nil)
+		}
+		return stopped
 	}
 }
 
-// Проверка отменен ли контекст, если да то есть ли ошибка
-func (mc *Context) Err() error {
-	mc.mu.Lock()
-	defer mc.mu.Unlock()
+// CancelCauseFunc отменяет контекст и запоминает причину отмены
+type CancelCauseFunc func(cause error)
 
-	select {
-	case <-mc.done:
-		return mc.err
-	default:
+// Как и в оригинале, родителем может быть любая реализация Context
+func WithCancel(parent Context) (Context, func()) {
+	child, cancel := WithCancelCause(parent)
+	return child, func() { cancel(Canceled) }
+}
+
+/*
+WithCancelCause работает как WithCancel, но cancel принимает причину отмены,
+которую потом можно достать через Cause. cancel(nil) запишет причиной Canceled
+*/
+func WithCancelCause(parent Context) (Context, CancelCauseFunc) {
+	child := newCancelContext(parent)
+	trackContext(child, "cancel", true)
+
+	cancel := func(cause error) {
+		child.safeCancel(true, Canceled, cause)
 	}
+	return child, cancel
+}
+
+/*
+Cause возвращает причину отмены: первую записанную причину вверх по цепочке
+родителей. Если причину никто не задавал, возвращает ctx.Err(), а для
+неотмененного контекста nil
+*/
+func Cause(ctx Context) error {
+	for c := ctx; c != nil; {
+		mc, ok := c.(*myContext)
+		if !ok {
+			// причины чужих контекстов знает только стандартная библиотека (если они ее)
+			if cause := context.Cause(c); cause != nil {
+				return cause
+			}
+			break
+		}
+
+		mc.mu.Lock()
+		cause := mc.cause
+		mc.mu.Unlock()
 
-	if mc.parent != nil {
-		if err := mc.parent.Err(); err != nil {
-			return err
+		if cause != nil {
+			return cause
 		}
+		c = mc.parent
 	}
+	return ctx.Err()
+}
 
-	return nil
+// Значения родителя остаются доступны через Value, а его отмена не распространяется
+func WithoutCancel(parent Context) Context {
+	child := newContext(parent)
+	child.detached = parent
+	trackContext(child, "without cancel", false)
+	return child
 }
 
-// Общая функция для обработки родительской отмены
-func (mc *Context) handleParentCancellation() bool {
-	if mc.parent != nil {
-		select {
-		case <-mc.parent.Done():
-			mc.mu.Lock()
-			defer mc.mu.Unlock()
-			select {
-			case <-mc.done:
-				return true
-			default:
-				if parentErr := mc.parent.Err(); parentErr != nil && mc.err == nil {
-					mc.err = parentErr
-				}
-				close(mc.done)
-				return true
-			}
-		default:
-		}
+/*
+WithValue возвращает дочерний контекст, в котором по key лежит val.
+Как и в стандартной библиотеке, key должен быть сравнимым и не nil.
+Своей отмены у него нет, Done и Err берутся у родителя
+*/
+func WithValue(parent Context, key, val any) Context {
+	if key == nil {
+		panic("nil key")
+	}
+	if !reflect.TypeOf(key).Comparable() {
+		panic("key is not comparable")
 	}
-	return false
+
+	child := newContext(parent)
+	child.parent = parent
+	child.key, child.val = key, val
+	if parent != nil {
+		child.deadline, _ = parent.Deadline()
+	}
+	trackContext(child, "value", false)
+	return child
 }
 
-// Общая функция для обработки таймера
-func (mc *Context) handleTimerCancellation() bool {
-	if mc.timer != nil {
-		select {
-		case <-mc.timer.C:
-			mc.mu.Lock()
-			defer mc.mu.Unlock()
-			select {
-			case <-mc.done:
-				return true
-			default:
-				if mc.err == nil {
-					mc.err = DeadlineExceeded
-				}
-				close(mc.done)
-				return true
-			}
-		default:
+// Value ищет ключ вверх по цепочке родителей, nil если ключа нигде нет
+func (mc *myContext) Value(key any) any {
+	var ctx Context = mc
+	for ctx != nil {
+		c, ok := ctx.(*myContext)
+		if !ok {
+			return ctx.Value(key)
+		}
+		if c.key != nil && c.key == key {
+			return c.val
+		}
+
+		if c.parent != nil {
+			ctx = c.parent
+		} else {
+			ctx = c.detached
 		}
 	}
-	return false
+	return nil
 }
 
-// Жертва единой структуры для разных контекстов) тут datarace, livelock можно найти
-func (mc *Context) Done() <-chan struct{} {
-	mc.mu.Lock()
-	select {
-	case <-mc.done:
-		mc.mu.Unlock()
-		return mc.done
-	default:
-		mc.mu.Unlock()
+/*
+AfterFunc как метод нужен стандартной библиотеке: context.WithCancel и другие,
+получив наш контекст родителем, подписываются на его отмену через этот метод
+без отдельной горутины
+*/
+func (mc *myContext) AfterFunc(f func()) (stop func() bool) {
+	return AfterFunc(mc, f)
+}
+
+/*
+FromStd строит контекст mycontext, повторяющий стандартный контекст parent:
+его отмену (вместе с причиной), дедлайн и значения. opts работают как в Background.
+cancel отвязывает результат от parent и отменяет его, вызывать обязательно.
+В отличие от WithCancel(parent) подписка идет через context.AfterFunc,
+так что для контекстов стандартной библиотеки лишней горутины не будет
+*/
+func FromStd(parent context.Context, opts ...Option) (Context, func()) {
+	ctx := newContext(newRoot(opts))
+	ctx.parent = parent
+	ctx.cancelable = true
+	ctx.deadline, _ = parent.Deadline()
+	trackContext(ctx, "from std", true)
+
+	if err := parent.Err(); err != nil {
+		ctx.safeCancel(false, err, context.Cause(parent))
+		return ctx, func() {}
+	}
+
+	ctx.stopParent = context.AfterFunc(parent, func() {
+		ctx.safeCancel(false, parent.Err(), context.Cause(parent))
+	})
+
+	cancel := func() {
+		ctx.safeCancel(true, Canceled, nil)
 	}
+	return ctx, cancel
+}
+
+func WithDeadline(parent Context, ddl time.Time) (Context, func()) {
+	return WithDeadlineCause(parent, ddl, nil)
+}
 
-	if mc.handleParentCancellation() {
-		return mc.done
+/*
+WithDeadlineCause работает как WithDeadline, но при истечении дедлайна
+Cause вернет cause. Отмена через возвращенный cancel причину не задает
+*/
+func WithDeadlineCause(parent Context, ddl time.Time, cause error) (Context, func()) {
+	/*
+		У предка дедлайн раньше: он и так отменит ребенка вовремя,
+		второй таймер не нужен, хватит обычного отменяемого контекста
+	*/
+	if parent != nil {
+		if cur, ok := parent.Deadline(); ok && cur.Before(ddl) {
+			return WithCancel(parent)
+		}
 	}
 
-	if mc.handleTimerCancellation() {
-		return mc.done
+	now := time.Now()
+	if now.After(ddl) {
+		child := newCancelContext(parent)
+		child.deadline = ddl
+		trackContext(child, "deadline", true)
+		child.safeCancel(true, DeadlineExceeded, cause)
+		return child, func() {}
 	}
 
+	child := newCancelContext(parent)
+	child.deadline = ddl
+	child.deadlineCause = cause
+	trackContext(child, "deadline", true)
+
+	/*
+		Раньше на каждый дедлайн запускалась горутина, которая ждала timer.C.
+		time.AfterFunc сам вызовет safeCancel из рантайма, горутина появится
+		только в момент срабатывания. Если родитель уже успел отменить
+		ребенка, таймер не заводим
+	*/
+	child.mu.Lock()
+	if child.err == nil {
+		child.timer = time.AfterFunc(time.Until(ddl), func() {
+			child.safeCancel(true, DeadlineExceeded, child.deadlineCause)
+		})
+	}
+	child.mu.Unlock()
+
+	cancel := func() {
+		child.safeCancel(true, Canceled, nil)
+	}
+	return child, cancel
+}
+
+func WithTimeout(parent Context, duration time.Duration) (Context, func()) {
+	return WithDeadline(parent, time.Now().Add(duration))
+}
+
+func WithTimeoutCause(parent Context, duration time.Duration, cause error) (Context, func()) {
+	return WithDeadlineCause(parent, time.Now().Add(duration), cause)
+}
+
+func Background(opts ...Option) Context {
+	ctx := newRoot(opts)
+	trackContext(ctx, "background", false)
+	return ctx
+}
+
+// Корневой контекст с примененными опциями
+func newRoot(opts []Option) *myContext {
+	ctx := newContext(nil)
+	for _, opt := range opts {
+		opt(ctx)
+	}
+	ctx.mu = ctx.newLocker()
+
+	return ctx
+}
+
+// Deadline возвращает дедлайн контекста (свой или ближайшего предка), ok = false если его нет
+func (mc *myContext) Deadline() (deadline time.Time, ok bool) {
+	return mc.deadline, !mc.deadline.IsZero()
+}
+
+// Проверка отменен ли контекст, если да то есть ли ошибка
+func (mc *myContext) Err() error {
+	if mc.key != nil && mc.parent != nil {
+		return mc.parent.Err()
+	}
+
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	return mc.err
+}
+
+/*
+Раньше тут была ленивая проверка родителя и таймера при каждом вызове
+(и datarace с livelock в придачу). Теперь отмена доходит до детей сразу
+в safeCancel, поэтому done достаточно просто вернуть
+*/
+func (mc *myContext) Done() <-chan struct{} {
+	if mc.key != nil && mc.parent != nil {
+		return mc.parent.Done()
+	}
 	return mc.done
 }
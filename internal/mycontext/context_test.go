@@ -1,13 +1,25 @@
 package mycontext
 
 import (
+	"context"
+	"errors"
+	"my_concurency/internal/linearizability"
+	"my_concurency/internal/mylocker"
+	"my_concurency/internal/mymutexcas"
+	"my_concurency/internal/mymutextic"
+	"runtime"
 	"sync"
 	"testing"
 	"time"
 )
 
+// Достает нашу реализацию, чтобы проверить внутреннее состояние
+func impl(ctx Context) *myContext {
+	return ctx.(*myContext)
+}
+
 func TestBackground(t *testing.T) {
-	ctx := Background() // ctx is *Context
+	ctx := Background() // ctx is Context
 
 	select {
 	case <-ctx.Done(): // This works with pointer receiver
@@ -292,7 +304,7 @@ func TestWithoutCancel_Isolation(t *testing.T) {
 	isolated := WithoutCancel(parent)
 
 	// Проверяем, что isolated не имеет родителя
-	if isolated.parent != nil {
+	if impl(isolated).parent != nil {
 		t.Error("WithoutCancel should create context without parent")
 	}
 
@@ -431,7 +443,7 @@ func TestParentCancellationPropagation(t *testing.T) {
 	parentCancel()
 
 	// Все контексты должны быть отменены
-	contexts := []*Context{parent, child, grandchild}
+	contexts := []Context{parent, child, grandchild}
 	for i, ctx := range contexts {
 		select {
 		case <-ctx.Done():
@@ -504,12 +516,12 @@ func TestBackgroundContextProperties(t *testing.T) {
 	ctx := Background()
 
 	// Background context should have no parent
-	if ctx.parent != nil {
+	if impl(ctx).parent != nil {
 		t.Error("Background context should not have a parent")
 	}
 
 	// Background context should have no timer
-	if ctx.timer != nil {
+	if impl(ctx).timer != nil {
 		t.Error("Background context should not have a timer")
 	}
 
@@ -569,3 +581,873 @@ func TestWithDeadline_NilParent(t *testing.T) {
 		// Expected
 	}
 }
+
+func TestWithLocker_InheritedByChildren(t *testing.T) {
+	root := Background(WithLocker(func() mylocker.Locker { return &mymutexcas.Mutex{} }))
+	child, cancel := WithCancel(root)
+	defer cancel()
+	timed, timedCancel := WithTimeout(child, time.Second)
+	defer timedCancel()
+	isolated := WithoutCancel(timed)
+
+	for i, ctx := range []Context{root, child, timed, isolated} {
+		if _, ok := impl(ctx).mu.(*mymutexcas.Mutex); !ok {
+			t.Errorf("Context at level %d should use mymutexcas.Mutex, got %T", i, impl(ctx).mu)
+		}
+	}
+
+	if _, ok := impl(Background()).mu.(*sync.Mutex); !ok {
+		t.Errorf("Background should use sync.Mutex by default, got %T", impl(Background()).mu)
+	}
+}
+
+func TestWithLocker_Cancellation(t *testing.T) {
+	lockers := map[string]mylocker.Factory{
+		"sync":       mylocker.Sync,
+		"mymutexcas": func() mylocker.Locker { return &mymutexcas.Mutex{} },
+		"mymutextic": func() mylocker.Locker { return &mymutextic.Mutex{} },
+	}
+
+	for name, factory := range lockers {
+		t.Run(name, func(t *testing.T) {
+			parent, parentCancel := WithCancel(Background(WithLocker(factory)))
+			child, childCancel := WithCancel(parent)
+			defer childCancel()
+
+			var wg sync.WaitGroup
+			for i := 0; i < 10; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					_ = child.Err()
+				}()
+			}
+
+			parentCancel()
+			wg.Wait()
+
+			<-child.Done()
+			if child.Err() != Canceled {
+				t.Errorf("Expected Canceled error for child, got %v", child.Err())
+			}
+		})
+	}
+}
+
+type testKey string
+
+func TestWithValue(t *testing.T) {
+	root := WithValue(Background(), testKey("user"), "alice")
+	ctx, cancel := WithCancel(root)
+	defer cancel()
+	ctx = WithValue(ctx, testKey("request"), 42)
+
+	if got := ctx.Value(testKey("user")); got != "alice" {
+		t.Errorf("Expected value from ancestor, got %v", got)
+	}
+	if got := ctx.Value(testKey("request")); got != 42 {
+		t.Errorf("Expected own value, got %v", got)
+	}
+	if got := ctx.Value(testKey("missing")); got != nil {
+		t.Errorf("Expected nil for missing key, got %v", got)
+	}
+	// Ключ другого типа с тем же значением не должен находиться
+	if got := ctx.Value("user"); got != nil {
+		t.Errorf("Expected nil for key of different type, got %v", got)
+	}
+}
+
+func TestWithValue_Shadowing(t *testing.T) {
+	parent := WithValue(Background(), testKey("k"), "parent")
+	child := WithValue(parent, testKey("k"), "child")
+
+	if got := child.Value(testKey("k")); got != "child" {
+		t.Errorf("Expected child value to shadow parent, got %v", got)
+	}
+	if got := parent.Value(testKey("k")); got != "parent" {
+		t.Errorf("Parent value should not change, got %v", got)
+	}
+}
+
+func TestWithValue_FollowsParentCancellation(t *testing.T) {
+	parent, cancel := WithCancel(Background())
+	ctx := WithValue(parent, testKey("k"), "v")
+
+	cancel()
+
+	select {
+	case <-ctx.Done():
+		// Expected
+	default:
+		t.Error("Value context should be done when parent is cancelled")
+	}
+
+	if ctx.Err() != Canceled {
+		t.Errorf("Expected Canceled error, got %v", ctx.Err())
+	}
+}
+
+func TestWithValue_InvalidKey(t *testing.T) {
+	tests := map[string]any{
+		"nil":            nil,
+		"not comparable": []int{1},
+	}
+
+	for name, key := range tests {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("WithValue should panic")
+				}
+			}()
+			WithValue(Background(), key, "v")
+		})
+	}
+}
+
+func TestWithoutCancel_KeepsValues(t *testing.T) {
+	parent, parentCancel := WithCancel(WithValue(Background(), testKey("k"), "v"))
+	isolated := WithoutCancel(parent)
+
+	parentCancel()
+
+	if got := isolated.Value(testKey("k")); got != "v" {
+		t.Errorf("WithoutCancel should keep parent values, got %v", got)
+	}
+	if isolated.Err() != nil {
+		t.Errorf("Expected nil error for isolated context, got %v", isolated.Err())
+	}
+}
+
+func TestWithCancelCause(t *testing.T) {
+	errShutdown := errors.New("shutdown")
+	ctx, cancel := WithCancelCause(Background())
+
+	if Cause(ctx) != nil {
+		t.Errorf("Expected nil cause before cancellation, got %v", Cause(ctx))
+	}
+
+	cancel(errShutdown)
+	cancel(errors.New("second cause")) // Повторная отмена не должна менять причину
+
+	if ctx.Err() != Canceled {
+		t.Errorf("Expected Canceled error, got %v", ctx.Err())
+	}
+	if Cause(ctx) != errShutdown {
+		t.Errorf("Expected %v cause, got %v", errShutdown, Cause(ctx))
+	}
+}
+
+func TestWithCancelCause_NilCause(t *testing.T) {
+	ctx, cancel := WithCancelCause(Background())
+	cancel(nil)
+
+	if Cause(ctx) != Canceled {
+		t.Errorf("Expected Canceled cause, got %v", Cause(ctx))
+	}
+}
+
+func TestCause_FromParent(t *testing.T) {
+	errShutdown := errors.New("shutdown")
+	parent, cancel := WithCancelCause(Background())
+	child, childCancel := WithCancel(parent)
+	defer childCancel()
+
+	cancel(errShutdown)
+	<-child.Done()
+
+	if child.Err() != Canceled {
+		t.Errorf("Expected Canceled error for child, got %v", child.Err())
+	}
+	if Cause(child) != errShutdown {
+		t.Errorf("Expected parent cause %v, got %v", errShutdown, Cause(child))
+	}
+}
+
+func TestCause_WithoutExplicitCause(t *testing.T) {
+	ctx, cancel := WithCancel(Background())
+	cancel()
+
+	if Cause(ctx) != Canceled {
+		t.Errorf("Expected Canceled cause, got %v", Cause(ctx))
+	}
+}
+
+func TestWithTimeoutCause(t *testing.T) {
+	errSlow := errors.New("backend too slow")
+	ctx, cancel := WithTimeoutCause(Background(), 20*time.Millisecond, errSlow)
+	defer cancel()
+
+	<-ctx.Done()
+
+	if ctx.Err() != DeadlineExceeded {
+		t.Errorf("Expected DeadlineExceeded error, got %v", ctx.Err())
+	}
+	if Cause(ctx) != errSlow {
+		t.Errorf("Expected %v cause, got %v", errSlow, Cause(ctx))
+	}
+}
+
+func TestWithDeadlineCause_AlreadyPassed(t *testing.T) {
+	errLate := errors.New("too late")
+	ctx, cancel := WithDeadlineCause(Background(), time.Now().Add(-time.Second), errLate)
+	defer cancel()
+
+	if ctx.Err() != DeadlineExceeded {
+		t.Errorf("Expected DeadlineExceeded error, got %v", ctx.Err())
+	}
+	if Cause(ctx) != errLate {
+		t.Errorf("Expected %v cause, got %v", errLate, Cause(ctx))
+	}
+}
+
+func TestWithDeadlineCause_CancelBeforeDeadline(t *testing.T) {
+	ctx, cancel := WithDeadlineCause(Background(), time.Now().Add(time.Second), errors.New("unused"))
+	cancel()
+
+	if Cause(ctx) != Canceled {
+		t.Errorf("Expected Canceled cause, got %v", Cause(ctx))
+	}
+}
+
+func TestParentCancellation_WakesBlockedWaiters(t *testing.T) {
+	parent, parentCancel := WithCancel(Background())
+	child, childCancel := WithCancel(parent)
+	defer childCancel()
+	grandchild, grandchildCancel := WithTimeout(child, time.Hour)
+	defer grandchildCancel()
+
+	// Канал берем заранее: раньше такой ожидающий никогда не просыпался
+	done := grandchild.Done()
+	woke := make(chan struct{})
+	go func() {
+		<-done
+		close(woke)
+	}()
+
+	parentCancel()
+
+	select {
+	case <-woke:
+		// Expected
+	case <-time.After(500 * time.Millisecond):
+		t.Fatal("Goroutine blocked on grandchild Done() was not woken by parent cancellation")
+	}
+
+	if grandchild.Err() != Canceled {
+		t.Errorf("Expected Canceled error for grandchild, got %v", grandchild.Err())
+	}
+}
+
+func TestWithCancel_CancelledParent(t *testing.T) {
+	parent, parentCancel := WithCancel(Background())
+	parentCancel()
+
+	child, childCancel := WithCancel(parent)
+	defer childCancel()
+
+	select {
+	case <-child.Done():
+		// Expected
+	default:
+		t.Error("Child of cancelled parent should be done immediately")
+	}
+}
+
+func TestChildRemovedFromParentOnCancel(t *testing.T) {
+	parent, parentCancel := WithCancel(Background())
+	defer parentCancel()
+
+	for i := 0; i < 100; i++ {
+		_, cancel := WithCancel(parent)
+		cancel()
+	}
+	_, deadlineCancel := WithTimeout(WithValue(parent, testKey("k"), "v"), time.Hour)
+	deadlineCancel()
+
+	impl(parent).mu.Lock()
+	children := len(impl(parent).children)
+	impl(parent).mu.Unlock()
+
+	if children != 0 {
+		t.Errorf("Cancelled children should be removed from parent, %d left", children)
+	}
+}
+
+func TestBackgroundDoesNotTrackChildren(t *testing.T) {
+	root := Background()
+	_, cancel := WithCancel(root)
+	defer cancel()
+
+	impl(root).mu.Lock()
+	defer impl(root).mu.Unlock()
+	if len(impl(root).children) != 0 {
+		t.Error("Background context can't be cancelled and should not track children")
+	}
+}
+
+func TestParentCancellation_ConcurrentChildren(t *testing.T) {
+	parent, parentCancel := WithCancel(Background())
+
+	var wg sync.WaitGroup
+	const goroutines = 50
+
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			child, cancel := WithCancel(parent)
+			defer cancel()
+			<-child.Done()
+		}()
+	}
+
+	time.Sleep(5 * time.Millisecond)
+	parentCancel()
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		// Success
+	case <-time.After(time.Second):
+		t.Error("Not all children were cancelled with parent")
+	}
+}
+
+func TestAfterFunc(t *testing.T) {
+	ctx, cancel := WithCancel(Background())
+	called := make(chan struct{})
+	stop := AfterFunc(ctx, func() { close(called) })
+
+	select {
+	case <-called:
+		t.Fatal("AfterFunc should not run before cancellation")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	cancel()
+
+	select {
+	case <-called:
+		// Expected
+	case <-time.After(500 * time.Millisecond):
+		t.Fatal("AfterFunc was not called after cancellation")
+	}
+
+	if stop() {
+		t.Error("stop should return false after f has been started")
+	}
+}
+
+func TestAfterFunc_Deadline(t *testing.T) {
+	ctx, cancel := WithTimeout(Background(), 20*time.Millisecond)
+	defer cancel()
+
+	called := make(chan struct{})
+	AfterFunc(ctx, func() { close(called) })
+
+	select {
+	case <-called:
+		// Expected
+	case <-time.After(500 * time.Millisecond):
+		t.Fatal("AfterFunc was not called after deadline")
+	}
+}
+
+func TestAfterFunc_ParentCancellation(t *testing.T) {
+	parent, cancel := WithCancel(Background())
+	child := WithValue(parent, testKey("k"), "v")
+
+	called := make(chan struct{})
+	AfterFunc(child, func() { close(called) })
+
+	cancel()
+
+	select {
+	case <-called:
+		// Expected
+	case <-time.After(500 * time.Millisecond):
+		t.Fatal("AfterFunc was not called after ancestor cancellation")
+	}
+}
+
+func TestAfterFunc_AlreadyCancelled(t *testing.T) {
+	ctx, cancel := WithTimeout(Background(), 0)
+	defer cancel()
+
+	called := make(chan struct{})
+	AfterFunc(ctx, func() { close(called) })
+
+	select {
+	case <-called:
+		// Expected
+	case <-time.After(500 * time.Millisecond):
+		t.Fatal("AfterFunc on cancelled context should run immediately")
+	}
+}
+
+func TestWithDeadline_AlreadyPassed_Children(t *testing.T) {
+	parent, cancel := WithTimeout(Background(), 0)
+	defer cancel()
+	child, childCancel := WithCancel(parent)
+	defer childCancel()
+
+	if child.Err() != DeadlineExceeded {
+		t.Errorf("Expected DeadlineExceeded error for child, got %v", child.Err())
+	}
+}
+
+func TestAfterFunc_Stop(t *testing.T) {
+	ctx, cancel := WithCancel(Background())
+	var calls sync.WaitGroup
+	calls.Add(1)
+	stop := AfterFunc(ctx, func() { calls.Done() })
+
+	if !stop() {
+		t.Error("First stop should return true")
+	}
+	if stop() {
+		t.Error("Second stop should return false")
+	}
+
+	cancel()
+	time.Sleep(20 * time.Millisecond)
+	calls.Done() // если f все таки запустилась, счетчик уйдет в минус и будет паника
+
+	impl(ctx).mu.Lock()
+	defer impl(ctx).mu.Unlock()
+	if len(impl(ctx).children) != 0 {
+		t.Error("Stopped AfterFunc should be removed from context")
+	}
+}
+
+func TestAfterFunc_StopRace(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		ctx, cancel := WithCancel(Background())
+		ran := make(chan struct{}, 1)
+		stop := AfterFunc(ctx, func() {
+			ran <- struct{}{}
+		})
+
+		stopped := make(chan bool)
+		go func() { stopped <- stop() }()
+		cancel()
+
+		if <-stopped {
+			select {
+			case <-ran:
+				t.Fatal("f should not run when stop returned true")
+			case <-time.After(time.Millisecond):
+			}
+		} else {
+			<-ran
+		}
+	}
+}
+
+func TestDeadline(t *testing.T) {
+	if _, ok := Background().Deadline(); ok {
+		t.Error("Background context should not have a deadline")
+	}
+
+	deadline := time.Now().Add(time.Hour)
+	ctx, cancel := WithDeadline(Background(), deadline)
+	defer cancel()
+
+	got, ok := ctx.Deadline()
+	if !ok || !got.Equal(deadline) {
+		t.Errorf("Expected deadline %v, got %v (ok=%v)", deadline, got, ok)
+	}
+}
+
+func TestDeadline_InheritedByChildren(t *testing.T) {
+	deadline := time.Now().Add(time.Hour)
+	parent, cancel := WithDeadline(Background(), deadline)
+	defer cancel()
+	child, childCancel := WithCancel(WithValue(parent, testKey("k"), "v"))
+	defer childCancel()
+
+	if got, ok := child.Deadline(); !ok || !got.Equal(deadline) {
+		t.Errorf("Expected inherited deadline %v, got %v (ok=%v)", deadline, got, ok)
+	}
+
+	if _, ok := WithoutCancel(parent).Deadline(); ok {
+		t.Error("WithoutCancel should drop parent deadline")
+	}
+}
+
+func TestWithDeadline_LaterThanParent(t *testing.T) {
+	parentDeadline := time.Now().Add(50 * time.Millisecond)
+	parent, cancel := WithDeadline(Background(), parentDeadline)
+	defer cancel()
+
+	child, childCancel := WithTimeout(parent, time.Hour)
+	defer childCancel()
+
+	if impl(child).timer != nil {
+		t.Error("Child with later deadline should not arm its own timer")
+	}
+	if got, _ := child.Deadline(); !got.Equal(parentDeadline) {
+		t.Errorf("Expected parent deadline %v, got %v", parentDeadline, got)
+	}
+
+	<-child.Done()
+	if child.Err() != DeadlineExceeded {
+		t.Errorf("Expected DeadlineExceeded error, got %v", child.Err())
+	}
+}
+
+func TestWithDeadline_EarlierThanParent(t *testing.T) {
+	parent, cancel := WithTimeout(Background(), time.Hour)
+	defer cancel()
+
+	deadline := time.Now().Add(20 * time.Millisecond)
+	child, childCancel := WithDeadline(parent, deadline)
+	defer childCancel()
+
+	if got, _ := child.Deadline(); !got.Equal(deadline) {
+		t.Errorf("Expected own deadline %v, got %v", deadline, got)
+	}
+
+	<-child.Done()
+	if child.Err() != DeadlineExceeded {
+		t.Errorf("Expected DeadlineExceeded error, got %v", child.Err())
+	}
+	if parent.Err() != nil {
+		t.Errorf("Parent should not be affected by child deadline, got %v", parent.Err())
+	}
+}
+
+func TestStdContext_FromMyContext(t *testing.T) {
+	parent, cancel := WithCancelCause(WithValue(Background(), testKey("k"), "v"))
+
+	var std context.Context = parent
+	child, childCancel := context.WithTimeout(std, time.Hour)
+	defer childCancel()
+
+	if got := child.Value(testKey("k")); got != "v" {
+		t.Errorf("Expected value through std context, got %v", got)
+	}
+
+	cancel(errors.New("shutdown"))
+
+	select {
+	case <-child.Done():
+		// Expected
+	case <-time.After(500 * time.Millisecond):
+		t.Fatal("Std child should be cancelled with mycontext parent")
+	}
+
+	// причину стандартная библиотека у чужого контекста достать не может, только ошибку
+	if child.Err() != context.Canceled {
+		t.Errorf("Expected context.Canceled error, got %v", child.Err())
+	}
+}
+
+func TestFromStd(t *testing.T) {
+	deadline := time.Now().Add(time.Hour)
+	std, stdCancel := context.WithDeadline(context.WithValue(context.Background(), testKey("k"), "v"), deadline)
+	errShutdown := errors.New("shutdown")
+	std, stdCancelCause := context.WithCancelCause(std)
+	defer stdCancel()
+
+	ctx, cancel := FromStd(std)
+	defer cancel()
+	child, childCancel := WithCancel(ctx)
+	defer childCancel()
+
+	if got := child.Value(testKey("k")); got != "v" {
+		t.Errorf("Expected value from std context, got %v", got)
+	}
+	if got, ok := child.Deadline(); !ok || !got.Equal(deadline) {
+		t.Errorf("Expected std deadline %v, got %v (ok=%v)", deadline, got, ok)
+	}
+
+	stdCancelCause(errShutdown)
+
+	select {
+	case <-child.Done():
+		// Expected
+	case <-time.After(500 * time.Millisecond):
+		t.Fatal("mycontext should be cancelled with std parent")
+	}
+
+	if child.Err() != context.Canceled {
+		t.Errorf("Expected context.Canceled error, got %v", child.Err())
+	}
+	if Cause(child) != errShutdown {
+		t.Errorf("Expected %v cause, got %v", errShutdown, Cause(child))
+	}
+}
+
+func TestFromStd_Cancel(t *testing.T) {
+	std, stdCancel := context.WithCancel(context.Background())
+	defer stdCancel()
+
+	ctx, cancel := FromStd(std, WithLocker(func() mylocker.Locker { return &mymutexcas.Mutex{} }))
+	if _, ok := impl(ctx).mu.(*mymutexcas.Mutex); !ok {
+		t.Errorf("FromStd should apply options, got %T", impl(ctx).mu)
+	}
+
+	cancel()
+
+	if ctx.Err() != Canceled {
+		t.Errorf("Expected Canceled error, got %v", ctx.Err())
+	}
+	if std.Err() != nil {
+		t.Errorf("Cancelling mycontext should not cancel std parent, got %v", std.Err())
+	}
+}
+
+func TestFromStd_AlreadyCancelled(t *testing.T) {
+	std, stdCancel := context.WithTimeout(context.Background(), 0)
+	defer stdCancel()
+
+	ctx, cancel := FromStd(std)
+	defer cancel()
+
+	if ctx.Err() != context.DeadlineExceeded {
+		t.Errorf("Expected context.DeadlineExceeded error, got %v", ctx.Err())
+	}
+}
+
+// Своя реализация Context, которую пакет не знает
+type foreignContext struct {
+	done     chan struct{}
+	mu       sync.Mutex
+	err      error
+	deadline time.Time
+	values   map[any]any
+}
+
+func newForeignContext() *foreignContext {
+	return &foreignContext{
+		done:   make(chan struct{}),
+		values: map[any]any{},
+	}
+}
+
+func (fc *foreignContext) Deadline() (time.Time, bool) {
+	return fc.deadline, !fc.deadline.IsZero()
+}
+
+func (fc *foreignContext) Done() <-chan struct{} {
+	return fc.done
+}
+
+func (fc *foreignContext) Err() error {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	return fc.err
+}
+
+func (fc *foreignContext) Value(key any) any {
+	return fc.values[key]
+}
+
+func (fc *foreignContext) cancel(err error) {
+	fc.mu.Lock()
+	fc.err = err
+	fc.mu.Unlock()
+	close(fc.done)
+}
+
+func TestForeignParent_Cancellation(t *testing.T) {
+	parent := newForeignContext()
+	child, cancel := WithCancel(parent)
+	defer cancel()
+	grandchild, grandchildCancel := WithTimeout(child, time.Hour)
+	defer grandchildCancel()
+
+	parent.cancel(Canceled)
+
+	select {
+	case <-grandchild.Done():
+		// Expected
+	case <-time.After(500 * time.Millisecond):
+		t.Fatal("Child of foreign parent should be cancelled with it")
+	}
+
+	if grandchild.Err() != Canceled {
+		t.Errorf("Expected Canceled error, got %v", grandchild.Err())
+	}
+	if Cause(grandchild) != Canceled {
+		t.Errorf("Expected Canceled cause, got %v", Cause(grandchild))
+	}
+}
+
+func TestForeignParent_AlreadyCancelled(t *testing.T) {
+	parent := newForeignContext()
+	parent.cancel(DeadlineExceeded)
+
+	child, cancel := WithCancel(parent)
+	defer cancel()
+
+	if child.Err() != DeadlineExceeded {
+		t.Errorf("Expected DeadlineExceeded error, got %v", child.Err())
+	}
+}
+
+func TestForeignParent_ValuesAndDeadline(t *testing.T) {
+	parent := newForeignContext()
+	parent.deadline = time.Now().Add(time.Hour)
+	parent.values[testKey("k")] = "v"
+
+	child, cancel := WithTimeout(parent, 2*time.Hour)
+	defer cancel()
+
+	if got := child.Value(testKey("k")); got != "v" {
+		t.Errorf("Expected value from foreign parent, got %v", got)
+	}
+	if got, _ := child.Deadline(); !got.Equal(parent.deadline) {
+		t.Errorf("Expected foreign parent deadline %v, got %v", parent.deadline, got)
+	}
+	if got := WithoutCancel(parent).Value(testKey("k")); got != "v" {
+		t.Errorf("WithoutCancel should keep foreign parent values, got %v", got)
+	}
+}
+
+func TestForeignParent_ChildCancelStopsWatcher(t *testing.T) {
+	parent := newForeignContext()
+	before := runtime.NumGoroutine()
+
+	for i := 0; i < 100; i++ {
+		_, cancel := WithCancel(parent)
+		cancel()
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	if after := runtime.NumGoroutine(); after > before+5 {
+		t.Errorf("Watcher goroutines should exit on child cancel: %d before, %d after", before, after)
+	}
+}
+
+func TestStdParent(t *testing.T) {
+	std, stdCancel := context.WithCancel(context.WithValue(context.Background(), testKey("k"), "v"))
+	child, cancel := WithCancel(std)
+	defer cancel()
+
+	if got := child.Value(testKey("k")); got != "v" {
+		t.Errorf("Expected value from std parent, got %v", got)
+	}
+
+	stdCancel()
+
+	select {
+	case <-child.Done():
+		// Expected
+	case <-time.After(500 * time.Millisecond):
+		t.Fatal("Child of std parent should be cancelled with it")
+	}
+}
+
+func TestWithDeadline_NoGoroutinePerContext(t *testing.T) {
+	const contexts = 10000
+	before := runtime.NumGoroutine()
+
+	parent, parentCancel := WithCancel(Background())
+	defer parentCancel()
+
+	cancels := make([]func(), 0, contexts)
+	for i := 0; i < contexts; i++ {
+		_, cancel := WithTimeout(parent, time.Hour)
+		cancels = append(cancels, cancel)
+	}
+
+	// Даем время запуститься горутинам, если бы они были
+	time.Sleep(10 * time.Millisecond)
+	if during := runtime.NumGoroutine(); during > before+10 {
+		t.Errorf("Deadline contexts should not park goroutines: %d before, %d with %d live contexts",
+			before, during, contexts)
+	}
+
+	for _, cancel := range cancels {
+		cancel()
+	}
+}
+
+func TestWithDeadline_TimerStoppedOnCancel(t *testing.T) {
+	ctx, cancel := WithTimeout(Background(), time.Hour)
+	cancel()
+
+	if impl(ctx).timer.Stop() {
+		t.Error("Timer should already be stopped after cancel")
+	}
+}
+
+/*
+Err и отмены на цепочке parent -> child -> grandchild линеаризуемы относительно
+последовательного контекста. Отмена предка пишется в историю каждого потомка:
+когда cancel вернулся, потомки уже обязаны отдавать Canceled
+*/
+func TestCancel_Linearizable(t *testing.T) {
+	const rounds, readers, reads = 50, 3, 20
+
+	for _, l := range benchLockers {
+		t.Run(l.name, func(t *testing.T) {
+			for round := 0; round < rounds; round++ {
+				parent, parentCancel := WithCancel(Background(WithLocker(l.factory)))
+				child, childCancel := WithCancel(parent)
+				grandchild, grandchildCancel := WithCancel(child)
+
+				contexts := []Context{parent, child, grandchild}
+				cancels := []func(){parentCancel, childCancel, grandchildCancel}
+				recorders := make([]*linearizability.Recorder, len(contexts))
+				for i := range recorders {
+					recorders[i] = linearizability.NewRecorder()
+				}
+
+				var wg sync.WaitGroup
+				for level, ctx := range contexts {
+					for r := 0; r < readers; r++ {
+						c := recorders[level].Client()
+						wg.Add(1)
+						go func() {
+							defer wg.Done()
+							for i := 0; i < reads; i++ {
+								c.Invoke(linearizability.Err)
+								c.Return(ctx.Err())
+								runtime.Gosched()
+							}
+						}()
+					}
+				}
+				for level, cancel := range cancels {
+					// отмена уровня level видна в историях его самого и всех потомков
+					var clients []*linearizability.Client
+					for _, rec := range recorders[level:] {
+						clients = append(clients, rec.Client())
+					}
+					wg.Add(1)
+					go func() {
+						defer wg.Done()
+						runtime.Gosched()
+						for _, c := range clients {
+							c.Invoke(linearizability.Cancel)
+						}
+						cancel()
+						for _, c := range clients {
+							c.Return(nil)
+						}
+					}()
+				}
+				wg.Wait()
+
+				for level, rec := range recorders {
+					history := rec.History()
+					if !linearizability.Check(linearizability.ContextModel(Canceled), history) {
+						for _, op := range history {
+							t.Log(op)
+						}
+						t.Fatalf("Round %d: history of context at level %d is not linearizable", round, level)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,313 @@
+package mycontext
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+/*
+Отладочный режим: пока он включен, каждый созданный контекст запоминается
+вместе со стеком создания. Это позволяет напечатать дерево контекстов (Dump)
+и найти контексты, для которых забыли вызвать cancel (CheckLeaks).
+Отмененные контексты остаются в дереве, но их число ограничено (см. sweepLocked),
+поэтому учет не растет в долгоживущем процессе.
+Выключенный режим стоит одну атомарную загрузку на создание контекста
+*/
+var debug struct {
+	enabled atomic.Bool
+	mu      sync.Mutex
+	seq     uint64
+	tracked map[*myContext]*trackInfo
+	// размер учета после прошлой чистки, см. sweepLocked
+	swept int
+}
+
+// Сколько отмененных контекстов держим в учете после чистки
+const maxCanceled = 1000
+
+type trackInfo struct {
+	id   uint64
+	kind string
+	// контекст создан вместе с cancel функцией, значит его обязаны отменить
+	needsCancel bool
+	stack       []uintptr
+}
+
+// Каталог пакета, чтобы отрезать из стека создания наши собственные кадры
+var packageDir = func() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Dir(file)
+}()
+
+// EnableDebug включает учет контекстов, созданных после вызова, нумерация начинается с #1
+func EnableDebug() {
+	debug.mu.Lock()
+	defer debug.mu.Unlock()
+
+	if debug.tracked == nil {
+		debug.tracked = make(map[*myContext]*trackInfo)
+		debug.seq = 0
+		debug.swept = 0
+	}
+	debug.enabled.Store(true)
+}
+
+// DisableDebug выключает учет и забывает все запомненные контексты
+func DisableDebug() {
+	debug.mu.Lock()
+	defer debug.mu.Unlock()
+
+	debug.enabled.Store(false)
+	debug.tracked = nil
+}
+
+func trackContext(mc *myContext, kind string, needsCancel bool) {
+	if !debug.enabled.Load() {
+		return
+	}
+
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(2, pcs)
+
+	debug.mu.Lock()
+	defer debug.mu.Unlock()
+	if debug.tracked == nil {
+		// режим успели выключить
+		return
+	}
+
+	debug.seq++
+	debug.tracked[mc] = &trackInfo{
+		id:          debug.seq,
+		kind:        kind,
+		needsCancel: needsCancel,
+		stack:       pcs[:n],
+	}
+	if len(debug.tracked) > 2*debug.swept+64 {
+		sweepLocked()
+	}
+}
+
+/*
+Чистка учета, идет, когда он вырос вдвое с прошлой, так что в среднем
+добавление остается O(1). Отмененный контекст (или WithValue под отмененным)
+выбрасывается, только когда в учете нет его родителя: до тех пор он нужен
+в дереве Dump. Если отмененных больше maxCanceled, сначала выбрасываются
+самые старые, а за ними по цепочке и их отмененные потомки
+*/
+func sweepLocked() {
+	for evictOrphans() {
+	}
+
+	var canceled []*myContext
+	for mc := range debug.tracked {
+		if mc.Err() != nil {
+			canceled = append(canceled, mc)
+		}
+	}
+	if extra := len(canceled) - maxCanceled; extra > 0 {
+		sort.Slice(canceled, func(i, j int) bool {
+			return debug.tracked[canceled[i]].id < debug.tracked[canceled[j]].id
+		})
+		for _, mc := range canceled[:extra] {
+			delete(debug.tracked, mc)
+		}
+		for evictOrphans() {
+		}
+	}
+	debug.swept = len(debug.tracked)
+}
+
+// Один проход: выбрасывает отмененные контексты без родителя в учете, true если что-то выбросил
+func evictOrphans() bool {
+	evicted := false
+	for mc := range debug.tracked {
+		if mc.Err() == nil {
+			continue
+		}
+		if _, ok := debug.tracked[mc.debugParent()]; !ok {
+			delete(debug.tracked, mc)
+			evicted = true
+		}
+	}
+	return evicted
+}
+
+// Кадры стека создания без кадров самого пакета (тесты пакета оставляем)
+func (ti *trackInfo) frames() []runtime.Frame {
+	var result []runtime.Frame
+	frames := runtime.CallersFrames(ti.stack)
+	for {
+		frame, more := frames.Next()
+		if filepath.Dir(frame.File) != packageDir || strings.HasSuffix(frame.File, "_test.go") {
+			result = append(result, frame)
+		}
+		if !more {
+			return result
+		}
+	}
+}
+
+func (ti *trackInfo) createdAt() string {
+	frames := ti.frames()
+	if len(frames) == 0 {
+		return "unknown"
+	}
+	return fmt.Sprintf("%s:%d", frames[0].File, frames[0].Line)
+}
+
+func (ti *trackInfo) formatStack() string {
+	var sb strings.Builder
+	for _, frame := range ti.frames() {
+		fmt.Fprintf(&sb, "\t%s\n\t\t%s:%d\n", frame.Function, frame.File, frame.Line)
+	}
+	return sb.String()
+}
+
+// Состояние контекста для Dump: active, canceled или deadline exceeded
+func (mc *myContext) debugState() string {
+	switch mc.Err() {
+	case nil:
+		return "active"
+	case DeadlineExceeded:
+		return "deadline exceeded"
+	default:
+		return "canceled"
+	}
+}
+
+// Родитель в дереве Dump: для WithoutCancel показываем того, от кого взяты значения
+func (mc *myContext) debugParent() *myContext {
+	parent := mc.parent
+	if parent == nil {
+		parent = mc.detached
+	}
+	p, _ := parent.(*myContext)
+	return p
+}
+
+/*
+Dump печатает в w дерево контекстов, созданных при включенном
+отладочном режиме: вид, состояние, оставшееся до дедлайна время и
+место создания. Контексты, чей родитель не отслеживается, выводятся корнями.
+Давно отмененные контексты могли быть уже выброшены из учета
+*/
+func Dump(w io.Writer) error {
+	debug.mu.Lock()
+	defer debug.mu.Unlock()
+
+	if debug.tracked == nil {
+		_, err := fmt.Fprintln(w, "mycontext: debug mode is disabled")
+		return err
+	}
+
+	children := make(map[*myContext][]*myContext)
+	var roots []*myContext
+	for mc := range debug.tracked {
+		parent := mc.debugParent()
+		if _, ok := debug.tracked[parent]; ok {
+			children[parent] = append(children[parent], mc)
+		} else {
+			roots = append(roots, mc)
+		}
+	}
+
+	byID := func(list []*myContext) {
+		sort.Slice(list, func(i, j int) bool {
+			return debug.tracked[list[i]].id < debug.tracked[list[j]].id
+		})
+	}
+
+	var dump func(mc *myContext, depth int) error
+	dump = func(mc *myContext, depth int) error {
+		info := debug.tracked[mc]
+		state := mc.debugState()
+		if deadline, ok := mc.Deadline(); ok && state == "active" {
+			state = fmt.Sprintf("%s, %v left", state, time.Until(deadline).Round(time.Millisecond))
+		}
+
+		_, err := fmt.Fprintf(w, "%s#%d %s: %s (created at %s)\n",
+			strings.Repeat("  ", depth), info.id, info.kind, state, info.createdAt())
+		if err != nil {
+			return err
+		}
+
+		byID(children[mc])
+		for _, child := range children[mc] {
+			if err := dump(child, depth+1); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	byID(roots)
+	for _, root := range roots {
+		if err := dump(root, 0); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// TB - часть testing.TB, которая нужна CheckLeaks, чтобы не тащить testing в пакет
+type TB interface {
+	Helper()
+	Errorf(format string, args ...any)
+	Cleanup(func())
+}
+
+/*
+CheckLeaks включает отладочный режим на время теста и по его окончании
+(после всех defer) проваливает тест, если какой-то контекст, созданный
+вместе с cancel функцией во время теста, так и не был отменен.
+С параллельными тестами не использовать: учет контекстов общий на пакет
+*/
+func CheckLeaks(t TB) {
+	t.Helper()
+
+	wasEnabled := debug.enabled.Load()
+	EnableDebug()
+
+	debug.mu.Lock()
+	start := debug.seq
+	debug.mu.Unlock()
+
+	t.Cleanup(func() {
+		t.Helper()
+
+		for _, leak := range leakedSince(start) {
+			t.Errorf("mycontext: %s context #%d was never cancelled, created at:\n%s",
+				leak.kind, leak.id, leak.formatStack())
+		}
+
+		if !wasEnabled {
+			DisableDebug()
+		}
+	})
+}
+
+// Неотмененные контексты с cancel функцией, созданные после номера start
+func leakedSince(start uint64) []*trackInfo {
+	debug.mu.Lock()
+	defer debug.mu.Unlock()
+
+	var leaks []*trackInfo
+	for mc, info := range debug.tracked {
+		if info.id > start && info.needsCancel && mc.Err() == nil {
+			leaks = append(leaks, info)
+		}
+	}
+
+	sort.Slice(leaks, func(i, j int) bool {
+		return leaks[i].id < leaks[j].id
+	})
+	return leaks
+}
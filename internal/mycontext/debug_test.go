@@ -0,0 +1,217 @@
+package mycontext
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Подменяет testing.T, чтобы проверить, что CheckLeaks действительно ругается
+type fakeTB struct {
+	errors   []string
+	cleanups []func()
+}
+
+func (f *fakeTB) Helper() {}
+
+func (f *fakeTB) Errorf(format string, args ...any) {
+	f.errors = append(f.errors, fmt.Sprintf(format, args...))
+}
+
+func (f *fakeTB) Cleanup(fn func()) {
+	f.cleanups = append(f.cleanups, fn)
+}
+
+func (f *fakeTB) finish() {
+	for i := len(f.cleanups) - 1; i >= 0; i-- {
+		f.cleanups[i]()
+	}
+}
+
+func TestCheckLeaks_NoLeaks(t *testing.T) {
+	CheckLeaks(t)
+
+	ctx, cancel := WithCancel(Background())
+	defer cancel()
+	_, timeoutCancel := WithTimeout(ctx, time.Hour)
+	defer timeoutCancel()
+	// Дети, отмененные вместе с родителем, утечкой не считаются
+	WithCancel(ctx)
+	WithValue(ctx, testKey("k"), "v")
+}
+
+func TestCheckLeaks_ReportsForgottenCancel(t *testing.T) {
+	tb := &fakeTB{}
+	CheckLeaks(tb)
+
+	_, cancel := WithCancel(Background())
+	cancel()
+	_, leakedCancel := WithTimeout(Background(), time.Hour)
+	defer leakedCancel()
+
+	tb.finish()
+
+	if len(tb.errors) != 1 {
+		t.Fatalf("Expected 1 leak, got %d: %v", len(tb.errors), tb.errors)
+	}
+	if !strings.Contains(tb.errors[0], "deadline context") ||
+		!strings.Contains(tb.errors[0], "TestCheckLeaks_ReportsForgottenCancel") {
+		t.Errorf("Leak report should name the context kind and creation stack, got:\n%s", tb.errors[0])
+	}
+	if debug.enabled.Load() {
+		t.Error("CheckLeaks should restore disabled debug mode")
+	}
+}
+
+func TestCheckLeaks_IgnoresContextsCreatedBefore(t *testing.T) {
+	EnableDebug()
+	defer DisableDebug()
+
+	_, cancel := WithCancel(Background())
+	defer cancel()
+
+	tb := &fakeTB{}
+	CheckLeaks(tb)
+	tb.finish()
+
+	if len(tb.errors) != 0 {
+		t.Errorf("Contexts created before CheckLeaks should be ignored, got %v", tb.errors)
+	}
+	if !debug.enabled.Load() {
+		t.Error("CheckLeaks should keep debug mode enabled if it was enabled before")
+	}
+}
+
+func TestDump(t *testing.T) {
+	EnableDebug()
+	defer DisableDebug()
+
+	root := Background()
+	parent, cancel := WithCancel(root)
+	defer cancel()
+	WithValue(parent, testKey("k"), "v")
+	_, timeoutCancel := WithTimeout(parent, time.Hour)
+	defer timeoutCancel()
+	canceled, canceledCancel := WithCancel(parent)
+	canceledCancel()
+	expired, expiredCancel := WithTimeout(canceled, 0)
+	defer expiredCancel()
+	_ = expired
+
+	var sb strings.Builder
+	if err := Dump(&sb); err != nil {
+		t.Fatalf("Dump failed: %v", err)
+	}
+	out := sb.String()
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+
+	expected := []string{
+		"#1 background: active",
+		"  #2 cancel: active",
+		"    #3 value: active",
+		"    #4 deadline: active, ",
+		"    #5 cancel: canceled",
+		"      #6 deadline: canceled",
+	}
+	if len(lines) != len(expected) {
+		t.Fatalf("Expected %d lines, got:\n%s", len(expected), out)
+	}
+	for i, prefix := range expected {
+		if !strings.HasPrefix(lines[i], prefix) {
+			t.Errorf("Line %d: expected prefix %q, got %q", i, prefix, lines[i])
+		}
+		if !strings.Contains(lines[i], "debug_test.go:") {
+			t.Errorf("Line %d should point to creation site, got %q", i, lines[i])
+		}
+	}
+}
+
+func TestDump_DeadlineExceeded(t *testing.T) {
+	EnableDebug()
+	defer DisableDebug()
+
+	ctx, cancel := WithTimeout(Background(), time.Millisecond)
+	defer cancel()
+	<-ctx.Done()
+
+	var sb strings.Builder
+	Dump(&sb)
+	if !strings.Contains(sb.String(), "#2 deadline: deadline exceeded") {
+		t.Errorf("Expected deadline exceeded state, got:\n%s", sb.String())
+	}
+}
+
+// Отмененные контексты под живым корнем ограничены maxCanceled, старые выбрасываются
+func TestDebug_BoundsCanceledContexts(t *testing.T) {
+	EnableDebug()
+	defer DisableDebug()
+
+	root, cancel := WithCancel(Background())
+	defer cancel()
+	for i := 0; i < 10*maxCanceled; i++ {
+		ctx, cancel := WithTimeout(root, time.Hour)
+		WithValue(ctx, testKey("k"), i)
+		cancel()
+	}
+
+	debug.mu.Lock()
+	tracked := len(debug.tracked)
+	_, rootTracked := debug.tracked[impl(root)]
+	debug.mu.Unlock()
+	// между чистками учет может вырасти вдвое
+	if tracked > 2*(maxCanceled+2)+64 {
+		t.Errorf("Canceled contexts should be bounded by %d, %d tracked", maxCanceled, tracked)
+	}
+	if !rootTracked {
+		t.Error("Live root should stay tracked")
+	}
+}
+
+// Отмененное поддерево уходит из учета, когда его родителя в учете уже нет
+func TestDebug_EvictsCanceledOrphans(t *testing.T) {
+	// корень создан до включения режима и не отслеживается
+	root := Background()
+	EnableDebug()
+	defer DisableDebug()
+
+	parent, cancel := WithCancel(root)
+	child, childCancel := WithCancel(parent)
+	defer childCancel()
+	WithValue(child, testKey("k"), "v")
+	live, liveCancel := WithCancel(root)
+	defer liveCancel()
+
+	debug.mu.Lock()
+	sweepLocked()
+	before := len(debug.tracked)
+	debug.mu.Unlock()
+	if before != 4 {
+		t.Fatalf("Live contexts should not be evicted, %d tracked", before)
+	}
+
+	cancel()
+	var sb strings.Builder
+	Dump(&sb)
+	if !strings.Contains(sb.String(), "#1 cancel: canceled") {
+		t.Errorf("Canceled context should stay in Dump until swept, got:\n%s", sb.String())
+	}
+
+	debug.mu.Lock()
+	sweepLocked()
+	_, liveTracked := debug.tracked[impl(live)]
+	after := len(debug.tracked)
+	debug.mu.Unlock()
+	if after != 1 || !liveTracked {
+		t.Errorf("Canceled subtree without tracked parent should be evicted, %d tracked", after)
+	}
+}
+
+func TestDump_Disabled(t *testing.T) {
+	var sb strings.Builder
+	Dump(&sb)
+
+	if !strings.Contains(sb.String(), "disabled") {
+		t.Errorf("Dump should report disabled debug mode, got %q", sb.String())
+	}
+}
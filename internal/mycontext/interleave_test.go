@@ -0,0 +1,77 @@
+//go:build interleave
+
+package mycontext
+
+import (
+	"errors"
+	"my_concurency/internal/interleave"
+	"my_concurency/internal/mylocker"
+	"my_concurency/internal/mymutexcas"
+	"testing"
+)
+
+/*
+Model checking под тегом interleave: go test -tags interleave ./internal/mycontext/
+Дерево контекстов работает на mymutexcas.Mutex, чье состояние под этим тегом
+инструментировано, так что точки переключения - захваты и освобождения мьютексов
+*/
+
+func interleaveRoot() Context {
+	return Background(WithLocker(func() mylocker.Locker { return &mymutexcas.Mutex{} }))
+}
+
+// Ребенок, созданный одновременно с отменой родителя, обязан оказаться отмененным
+func TestContext_Interleave_CancelWhileCreatingChild(t *testing.T) {
+	setup := func() ([]func(), func() error) {
+		parent, cancel := WithCancel(interleaveRoot())
+		var child Context
+
+		threads := []func(){
+			cancel,
+			func() { child, _ = WithCancel(parent) },
+		}
+		check := func() error {
+			if child.Err() == nil {
+				return errors.New("child of a cancelled parent is not cancelled")
+			}
+			if len(impl(parent).children) != 0 {
+				return errors.New("cancelled parent still holds children")
+			}
+			return nil
+		}
+		return threads, check
+	}
+
+	res := interleave.Explore(interleave.Config{Depth: 12}, setup)
+	if res.Failure != nil {
+		t.Fatal(res.Failure)
+	}
+}
+
+// Отмена ребенка и родителя одновременно: ребенок уходит из children родителя, и никто не зависает
+func TestContext_Interleave_CancelChildAndParent(t *testing.T) {
+	setup := func() ([]func(), func() error) {
+		parent, cancelParent := WithCancel(interleaveRoot())
+		child, cancelChild := WithCancel(parent)
+		grandchild, cancelGrandchild := WithCancel(child)
+
+		threads := []func(){cancelParent, cancelChild, cancelGrandchild}
+		check := func() error {
+			for _, ctx := range []Context{parent, child, grandchild} {
+				if ctx.Err() != Canceled {
+					return errors.New("context is not cancelled")
+				}
+			}
+			if len(impl(parent).children) != 0 || len(impl(child).children) != 0 {
+				return errors.New("cancelled context still holds children")
+			}
+			return nil
+		}
+		return threads, check
+	}
+
+	res := interleave.Explore(interleave.Config{Depth: 8}, setup)
+	if res.Failure != nil {
+		t.Fatal(res.Failure)
+	}
+}
@@ -0,0 +1,166 @@
+package mylocker_test
+
+import (
+	"fmt"
+	"my_concurency/internal/mylocker"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+/*
+Бенчмарки всех реализаций mylocker.Locker. Запуск:
+
+	go test -run '^$' -bench . -count 10 -cpu 1,4,8 ./internal/mylocker/ > new.txt
+	benchstat old.txt new.txt
+
+-cpu задает GOMAXPROCS, число горутин задается в самих бенчмарках
+*/
+
+var goroutineCounts = []int{1, 4, 16, 64}
+
+// Итерации пустой работы внутри критической секции
+var criticalSections = []struct {
+	name string
+	work int
+}{
+	{"short", 0},
+	{"long", 1000},
+}
+
+// Сюда складываем результат работы, чтобы компилятор ее не выкинул
+var sink atomic.Int64
+
+// Фабрики в одном порядке, чтобы вывод разных запусков легко сравнивать глазами
+func sortedFactories() ([]string, map[string]mylocker.Factory) {
+	all := factories()
+	names := make([]string, 0, len(all))
+	for name := range all {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, all
+}
+
+func work(n int) int {
+	acc := 0
+	for i := 0; i < n; i++ {
+		acc += i ^ acc
+	}
+	return acc
+}
+
+// Делит b.N операций между goroutines горутинами, каждая операция - Lock, работа, Unlock
+func runContended(b *testing.B, mu mylocker.Locker, goroutines, critical int) {
+	var counter int
+	var wg sync.WaitGroup
+
+	b.ResetTimer()
+	for g := 0; g < goroutines; g++ {
+		ops := b.N / goroutines
+		if g < b.N%goroutines {
+			ops++
+		}
+		wg.Add(1)
+		go func(ops int) {
+			defer wg.Done()
+			for i := 0; i < ops; i++ {
+				mu.Lock()
+				counter += work(critical)
+				counter++
+				mu.Unlock()
+			}
+		}(ops)
+	}
+	wg.Wait()
+	b.StopTimer()
+
+	sink.Add(int64(counter))
+}
+
+func BenchmarkLocker_Uncontended(b *testing.B) {
+	names, all := sortedFactories()
+	for _, name := range names {
+		b.Run(name, func(b *testing.B) {
+			mu := all[name]()
+			for i := 0; i < b.N; i++ {
+				mu.Lock()
+				mu.Unlock()
+			}
+		})
+	}
+}
+
+func BenchmarkLocker_Contended(b *testing.B) {
+	names, all := sortedFactories()
+	for _, name := range names {
+		for _, cs := range criticalSections {
+			for _, goroutines := range goroutineCounts {
+				b.Run(fmt.Sprintf("%s/%s/goroutines=%d", name, cs.name, goroutines), func(b *testing.B) {
+					runContended(b, all[name](), goroutines, cs.work)
+				})
+			}
+		}
+	}
+}
+
+func BenchmarkLocker_TryLockHit(b *testing.B) {
+	names, all := sortedFactories()
+	for _, name := range names {
+		b.Run(name, func(b *testing.B) {
+			mu := all[name]()
+			for i := 0; i < b.N; i++ {
+				if !mu.TryLock() {
+					b.Fatal("TryLock should succeed on unlocked locker")
+				}
+				mu.Unlock()
+			}
+		})
+	}
+}
+
+func BenchmarkLocker_TryLockMiss(b *testing.B) {
+	names, all := sortedFactories()
+	for _, name := range names {
+		b.Run(name, func(b *testing.B) {
+			mu := all[name]()
+			mu.Lock()
+			defer mu.Unlock()
+
+			for i := 0; i < b.N; i++ {
+				if mu.TryLock() {
+					b.Fatal("TryLock should fail on locked locker")
+				}
+			}
+		})
+	}
+}
+
+// Под нагрузкой из RunParallel: метрика hit-rate - доля успешных TryLock
+func BenchmarkLocker_TryLockContended(b *testing.B) {
+	names, all := sortedFactories()
+	for _, name := range names {
+		b.Run(name, func(b *testing.B) {
+			mu := all[name]()
+			var hits, total atomic.Int64
+
+			b.RunParallel(func(pb *testing.PB) {
+				var h, n int64
+				for pb.Next() {
+					n++
+					if mu.TryLock() {
+						h++
+						mu.Unlock()
+					}
+				}
+				hits.Add(h)
+				total.Add(n)
+			})
+
+			if n := total.Load(); n > 0 {
+				b.ReportMetric(float64(hits.Load())/float64(n), "hit-rate")
+			}
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package mylocker
+
+import "sync"
+
+/*
+Locker - общий интерфейс для всех мьютексов в репозитории.
+sync.Mutex, mymutexcas.Mutex и mymutextic.Mutex его реализуют,
+поэтому их можно подставлять друг вместо друга, например в mycontext
+*/
+type Locker interface {
+	Lock()
+	Unlock()
+	TryLock() bool
+}
+
+// RWLocker - Locker, который еще умеет пускать несколько читателей сразу
+type RWLocker interface {
+	Locker
+	RLock()
+	RUnlock()
+	TryRLock() bool
+}
+
+// Factory создает новый незахваченный Locker
+type Factory func() Locker
+
+// Sync - фабрика для sync.Mutex из стандартной библиотеки
+func Sync() Locker {
+	return &sync.Mutex{}
+}
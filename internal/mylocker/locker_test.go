@@ -0,0 +1,41 @@
+package mylocker_test
+
+import (
+	"my_concurency/internal/mylocker"
+	"my_concurency/internal/mymutexcas"
+	"my_concurency/internal/mymutexclh"
+	"my_concurency/internal/mymutexhybrid"
+	"my_concurency/internal/mymutexmcs"
+	"my_concurency/internal/mymutextic"
+	"testing"
+)
+
+func factories() map[string]mylocker.Factory {
+	return map[string]mylocker.Factory{
+		"sync":          mylocker.Sync,
+		"mymutexcas":    func() mylocker.Locker { return &mymutexcas.Mutex{} },
+		"mymutextic":    func() mylocker.Locker { return &mymutextic.Mutex{} },
+		"mymutexmcs":    func() mylocker.Locker { return &mymutexmcs.Mutex{} },
+		"mymutexclh":    func() mylocker.Locker { return &mymutexclh.Mutex{} },
+		"mymutexhybrid": func() mylocker.Locker { return &mymutexhybrid.Mutex{} },
+	}
+}
+
+func TestLocker_Factories(t *testing.T) {
+	for name, factory := range factories() {
+		t.Run(name, func(t *testing.T) {
+			mu := factory()
+			if !mu.TryLock() {
+				t.Error("TryLock should succeed on a fresh locker")
+			}
+			if mu.TryLock() {
+				t.Error("TryLock should fail on locked locker")
+			}
+			mu.Unlock()
+
+			if factory() == mu {
+				t.Error("Factory should return a new locker on every call")
+			}
+		})
+	}
+}
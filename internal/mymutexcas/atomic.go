@@ -0,0 +1,8 @@
+//go:build !interleave
+
+package mymutexcas
+
+import "sync/atomic"
+
+// Слово состояния Mutex. Под тегом interleave - инструментированная версия, см. atomic_interleave.go
+type atomicBool = atomic.Bool
@@ -0,0 +1,8 @@
+//go:build interleave
+
+package mymutexcas
+
+import "my_concurency/internal/interleave"
+
+// Сборка для model checking: каждая операция над state - точка переключения планировщика interleave
+type atomicBool = interleave.Bool
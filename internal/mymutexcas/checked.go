@@ -0,0 +1,58 @@
+package mymutexcas
+
+import (
+	"fmt"
+	"my_concurency/internal/goid"
+)
+
+/*
+WithOwnerCheck включает режим проверок: мьютекс запоминает горутину-владельца
+и паникует при Unlock свободного мьютекса, при Unlock из чужой горутины и
+при повторном Lock владельцем, который иначе просто повиснет навсегда.
+Номер горутины достается через runtime.Stack, так что режим для отладки и тестов
+*/
+func WithOwnerCheck() Option {
+	return func(mu *Mutex) {
+		mu.checked = true
+	}
+}
+
+/*
+Номер текущей горутины, 0 без режима проверок. Достаем его до захвата:
+runtime.Stack не быстрый, и все это время мьютекс держали бы зря
+*/
+func (mu *Mutex) self() int64 {
+	if !mu.checked {
+		return 0
+	}
+	return goid.ID()
+}
+
+func (mu *Mutex) setOwner(me int64) {
+	if mu.checked {
+		mu.owner.Store(me)
+	}
+}
+
+// Возвращает номер текущей горутины для setOwner
+func (mu *Mutex) checkRecursive() int64 {
+	me := mu.self()
+	if mu.checked && mu.owner.Load() == me {
+		panic("mymutexcas: recursive Lock by the goroutine that holds the mutex")
+	}
+	return me
+}
+
+// Владельца сбрасываем до освобождения state, чтобы следующий владелец не увидел старого
+func (mu *Mutex) checkUnlock() {
+	if !mu.checked {
+		return
+	}
+	if !mu.state.Load() {
+		panic("mymutexcas: unlock of unlocked mutex")
+	}
+	if owner, me := mu.owner.Load(), goid.ID(); owner != me {
+		panic(fmt.Sprintf("mymutexcas: unlock by goroutine %d of mutex held by goroutine %d", me, owner))
+	}
+	mu.owner.Store(0)
+}
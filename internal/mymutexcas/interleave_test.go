@@ -0,0 +1,80 @@
+//go:build interleave
+
+package mymutexcas
+
+import (
+	"errors"
+	"my_concurency/internal/interleave"
+	"my_concurency/internal/mylocker"
+	"sync/atomic"
+	"testing"
+)
+
+/*
+Model checking под тегом interleave: go test -tags interleave ./internal/mymutexcas/
+Каждая операция над state - точка переключения, Explore перебирает чередования
+*/
+
+// Потоки по очереди заходят в критическую секцию через lock
+func exclusionSetup(newLocker func() mylocker.Locker, lock func(mylocker.Locker)) interleave.Setup {
+	return func() ([]func(), func() error) {
+		mu := newLocker()
+		var inside atomic.Int32
+		var counter int
+
+		thread := func() {
+			lock(mu)
+			if inside.Add(1) != 1 {
+				panic("two goroutines in critical section")
+			}
+			// точка переключения внутри критической секции, иначе ее некому нарушить
+			interleave.Yield("critical section")
+			counter++
+			inside.Add(-1)
+			mu.Unlock()
+		}
+		check := func() error {
+			if counter != 3 {
+				return errors.New("lost update")
+			}
+			if mu.TryLock() {
+				mu.Unlock()
+				return nil
+			}
+			return errors.New("mutex is left locked")
+		}
+		return []func(){thread, thread, thread}, check
+	}
+}
+
+func TestMutexCAS_Interleave_Lock(t *testing.T) {
+	for name, backoff := range backoffs() {
+		t.Run(name, func(t *testing.T) {
+			setup := exclusionSetup(
+				func() mylocker.Locker { return NewMutex(WithBackoff(backoff), WithSpinCount(2, 2)) },
+				mylocker.Locker.Lock,
+			)
+			res := interleave.Explore(interleave.Config{Depth: 7}, setup)
+			if res.Failure != nil {
+				t.Fatal(res.Failure)
+			}
+			if !res.Complete {
+				t.Errorf("Expected all schedules within depth to be explored, stopped after %d", res.Runs)
+			}
+		})
+	}
+}
+
+func TestMutexCAS_Interleave_TryLock(t *testing.T) {
+	setup := exclusionSetup(
+		func() mylocker.Locker { return &Mutex{} },
+		func(mu mylocker.Locker) {
+			for !mu.TryLock() {
+			}
+		},
+	)
+	res := interleave.Explore(interleave.Config{Depth: 7}, setup)
+	if res.Failure != nil {
+		t.Fatal(res.Failure)
+	}
+}
@@ -1,8 +1,12 @@
 package mymutexcas
 
 import (
+	"context"
+	"my_concurency/internal/lockstats"
+	"my_concurency/internal/mylocker"
 	"runtime"
 	"sync/atomic"
+	"time"
 )
 
 const (
@@ -12,8 +16,27 @@ const (
 	spinCountTryLock = 10
 )
 
+var _ mylocker.Locker = (*Mutex)(nil)
+
+/*
+Нулевое значение - свободный мьютекс с прежним поведением: CompareAndSwap
+в плотном цикле, spinCountLock попыток до runtime.Gosched.
+Стратегию ожидания и бюджеты можно поменять через NewMutex
+*/
 type Mutex struct {
-	state atomic.Bool
+	state atomicBool
+
+	backoff Backoff
+	// 0 - значения по умолчанию spinCountLock и spinCountTryLock
+	spinLock    int
+	spinTryLock int
+
+	// режим проверок WithOwnerCheck, owner - номер горутины-владельца или 0
+	checked bool
+	owner   atomic.Int64
+
+	// статистика WithStats, nil - выключена
+	stats *lockstats.Recorder
 }
 
 func (mu *Mutex) Lock() {
@@ -24,27 +47,126 @@ func (mu *Mutex) Lock() {
 		переводим горутину в runabler,
 		т.е. переводится в конец очереди горутин.
 	*/
-	counter := spinCountLock
-	for !mu.state.CompareAndSwap(unlocked, locked) {
+	me := mu.checkRecursive()
+	if mu.state.CompareAndSwap(unlocked, locked) {
+		mu.setOwner(me)
+		if mu.stats != nil {
+			mu.stats.Acquired(0, 0, 0)
+		}
+		return
+	}
+	mu.lockSlow(me, nil)
+}
+
+/*
+LockContext захватывает мьютекс как Lock, но бросает ожидание, как только
+закроется ctx.Done(), и возвращает ctx.Err(). Принимает и mycontext.Context,
+и контекст из стандартной библиотеки. Если ctx уже отменен, мьютекс не захватывается
+*/
+func (mu *Mutex) LockContext(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	me := mu.checkRecursive()
+	done := ctx.Done()
+	aborted := mu.lockSlow(me, func() bool {
+		select {
+		case <-done:
+			return true
+		default:
+			return false
+		}
+	})
+	if aborted {
+		return ctx.Err()
+	}
+	return nil
+}
+
+/*
+TryLockUntil пытается захватить мьютекс до момента deadline,
+ожидая так же, как Lock. Возвращает true, если мьютекс захвачен
+*/
+func (mu *Mutex) TryLockUntil(deadline time.Time) bool {
+	me := mu.checkRecursive()
+	return !mu.lockSlow(me, func() bool {
+		return !time.Now().Before(deadline)
+	})
+}
+
+// TryLockFor пытается захватить мьютекс в течение d
+func (mu *Mutex) TryLockFor(d time.Duration) bool {
+	return mu.TryLockUntil(time.Now().Add(d))
+}
+
+/*
+Цикл ожидания Lock: между неудачными попытками выдерживаем паузу
+по стратегии backoff, после бюджета spinLock спрашиваем abort,
+стоит ли ждать дальше, и уходим в runtime.Gosched.
+abort может быть nil. true - сдались, мьютекс не захвачен.
+me - номер горутины из checkRecursive, его достаем до ожидания
+*/
+func (mu *Mutex) lockSlow(me int64, abort func() bool) bool {
+	var start time.Time
+	if mu.stats != nil {
+		start = time.Now()
+	}
+	var failed, yields uint64
+
+	budget := spinBudget(mu.spinLock, spinCountLock)
+	counter := budget
+	delay := minBackoffDelay
+	for !mu.tryAcquire() {
+		failed++
 		counter--
 		if counter == 0 {
+			if abort != nil && abort() {
+				if mu.stats != nil {
+					mu.stats.Failed(failed, yields)
+				}
+				return true
+			}
 			runtime.Gosched()
-			counter = spinCountLock
+			yields++
+			counter = budget
+			continue
 		}
+		delay = mu.pause(delay)
+	}
+	mu.setOwner(me)
+	if mu.stats != nil {
+		mu.stats.Acquired(time.Since(start), failed, yields)
 	}
+	return false
 }
 
 func (mu *Mutex) TryLock() bool {
-	counter := spinCountTryLock
-	for !mu.state.CompareAndSwap(unlocked, locked) {
+	me := mu.self()
+	budget := spinBudget(mu.spinTryLock, spinCountTryLock)
+	counter := budget
+	delay := minBackoffDelay
+	for !mu.tryAcquire() {
 		counter--
 		if counter == 0 {
-			counter = spinCountTryLock
+			if mu.stats != nil {
+				mu.stats.Failed(uint64(budget), 0)
+			}
 			return false
 		}
+		delay = mu.pause(delay)
+	}
+	mu.setOwner(me)
+	if mu.stats != nil {
+		mu.stats.Acquired(0, uint64(budget-counter), 0)
 	}
 	return true
 }
+
 func (mu *Mutex) Unlock() {
+	mu.checkUnlock()
+	if mu.stats != nil {
+		mu.stats.Released()
+	}
 	mu.state.Store(unlocked)
 }
@@ -1,78 +1,41 @@
 package mymutexcas
 
 import (
-	"sync"
+	"my_concurency/internal/conformance"
+	"my_concurency/internal/fairness"
+	"my_concurency/internal/mylocker"
 	"testing"
+	"time"
 )
 
-func TestMutexCAS_LockUnlock(t *testing.T) {
-	var mu Mutex
-	mu.Lock()
-	mu.Unlock()
-	// Should not panic
-}
-
-func TestMutexCAS_ConcurrentAccess(t *testing.T) {
-	var mu Mutex
-	var counter int
-	var wg sync.WaitGroup
-	iterations := 1000
-
-	for i := 0; i < iterations; i++ {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			mu.Lock()
-			counter++
-			mu.Unlock()
-		}()
-	}
-	wg.Wait()
-
-	if counter != iterations {
-		t.Errorf("Expected %d, got %d", iterations, counter)
-	}
-}
-
-func TestMutexCAS_TryLockSuccess(t *testing.T) {
-	var mu Mutex
-	if !mu.TryLock() {
-		t.Error("TryLock should succeed on unlocked mutex")
-	}
-	mu.Unlock()
-}
-
-func TestMutexCAS_TryLockFailure(t *testing.T) {
+func TestMutexCAS_Reentrancy(t *testing.T) {
 	var mu Mutex
 	mu.Lock()
 
-	// TryLock should fail when mutex is already locked
+	// TryLock should fail - mutex is not reentrant
 	if mu.TryLock() {
-		t.Error("TryLock should fail on locked mutex")
+		t.Error("Mutex should not be reentrant")
 	}
 
 	mu.Unlock()
 }
 
-func TestMutexCAS_TryLockAfterUnlock(t *testing.T) {
+// CAS lock порядок не гарантирует, отчет только для сравнения с ticket lock (go test -v)
+func TestMutexCAS_FairnessReport(t *testing.T) {
 	var mu Mutex
-	mu.Lock()
-	mu.Unlock()
-
-	if !mu.TryLock() {
-		t.Error("TryLock should succeed after unlock")
+	report := fairness.Run(&mu, fairness.Config{
+		Goroutines: 4,
+		Duration:   50 * time.Millisecond,
+		Work:       100,
+		Yield:      true,
+	})
+	t.Log(report)
+
+	if report.Total == 0 {
+		t.Error("Goroutines should acquire the mutex")
 	}
-	mu.Unlock()
 }
 
-func TestMutexCAS_Reentrancy(t *testing.T) {
-	var mu Mutex
-	mu.Lock()
-
-	// TryLock should fail - mutex is not reentrant
-	if mu.TryLock() {
-		t.Error("Mutex should not be reentrant")
-	}
-
-	mu.Unlock()
+func TestMutexCAS_Conformance(t *testing.T) {
+	conformance.Run(t, func() mylocker.Locker { return &Mutex{} })
 }
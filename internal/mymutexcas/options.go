@@ -0,0 +1,100 @@
+package mymutexcas
+
+import (
+	"math/rand/v2"
+	"my_concurency/internal/lockstats"
+	"my_concurency/internal/spin"
+)
+
+// Backoff - стратегия ожидания между неудачными попытками захвата
+type Backoff int
+
+const (
+	// BackoffNone: CompareAndSwap в плотном цикле, поведение нулевого Mutex
+	BackoffNone Backoff = iota
+	/*
+		BackoffExponential: после каждой неудачи пауза случайной длины
+		до delay, delay удваивается до maxBackoffDelay. Случайность
+		разводит горутины, чтобы они не били в кеш-линию одновременно
+	*/
+	BackoffExponential
+	/*
+		BackoffTTAS: test-and-test-and-set, пока мьютекс занят, только
+		читаем state и идем на CompareAndSwap, когда он освободился.
+		Чтение не отбирает кеш-линию у других ядер, в отличие от CAS
+	*/
+	BackoffTTAS
+)
+
+const (
+	minBackoffDelay = 4
+	maxBackoffDelay = 1024
+)
+
+// Option настраивает Mutex, созданный через NewMutex
+type Option func(*Mutex)
+
+func WithBackoff(b Backoff) Option {
+	return func(mu *Mutex) {
+		mu.backoff = b
+	}
+}
+
+// WithSpinCount задает, сколько попыток делает Lock до runtime.Gosched и сколько всего TryLock
+func WithSpinCount(lock, tryLock int) Option {
+	return func(mu *Mutex) {
+		mu.spinLock = lock
+		mu.spinTryLock = tryLock
+	}
+}
+
+/*
+WithStats включает статистику: захваты, неудачные CompareAndSwap, runtime.Gosched,
+гистограмма ожидания и самое долгое удержание. Снимок отдает Stats
+*/
+func WithStats() Option {
+	return func(mu *Mutex) {
+		mu.stats = lockstats.NewRecorder()
+	}
+}
+
+func NewMutex(opts ...Option) *Mutex {
+	mu := &Mutex{}
+	for _, opt := range opts {
+		opt(mu)
+	}
+	return mu
+}
+
+// Stats возвращает снимок статистики, без WithStats - нулевой
+func (mu *Mutex) Stats() lockstats.Stats {
+	if mu.stats == nil {
+		return lockstats.Stats{}
+	}
+	return mu.stats.Snapshot()
+}
+
+// Бюджет из настроек или значение по умолчанию, если не задан
+func spinBudget(configured, fallback int) int {
+	if configured > 0 {
+		return configured
+	}
+	return fallback
+}
+
+func (mu *Mutex) tryAcquire() bool {
+	if mu.backoff == BackoffTTAS && mu.state.Load() == locked {
+		return false
+	}
+	return mu.state.CompareAndSwap(unlocked, locked)
+}
+
+// Пауза после неудачной попытки, возвращает delay для следующей
+func (mu *Mutex) pause(delay int) int {
+	if mu.backoff != BackoffExponential {
+		return delay
+	}
+
+	spin.Pause(rand.IntN(delay) + 1)
+	return min(delay*2, maxBackoffDelay)
+}
@@ -0,0 +1,148 @@
+package mymutexcas
+
+import (
+	"context"
+	"my_concurency/internal/conformance"
+	"my_concurency/internal/mylocker"
+	"sync"
+	"testing"
+	"time"
+)
+
+func backoffs() map[string]Backoff {
+	return map[string]Backoff{
+		"none":        BackoffNone,
+		"exponential": BackoffExponential,
+		"ttas":        BackoffTTAS,
+	}
+}
+
+func TestMutexCAS_NewMutexDefaults(t *testing.T) {
+	mu := NewMutex()
+	if mu.backoff != BackoffNone || mu.spinLock != 0 || mu.spinTryLock != 0 {
+		t.Errorf("NewMutex without options should behave like the zero value, got %+v", mu)
+	}
+}
+
+func TestMutexCAS_Backoff_ConcurrentAccess(t *testing.T) {
+	for name, backoff := range backoffs() {
+		t.Run(name, func(t *testing.T) {
+			mu := NewMutex(WithBackoff(backoff), WithSpinCount(16, 4))
+			var counter int
+			var wg sync.WaitGroup
+			iterations := 1000
+
+			for i := 0; i < iterations; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					mu.Lock()
+					counter++
+					mu.Unlock()
+				}()
+			}
+			wg.Wait()
+
+			if counter != iterations {
+				t.Errorf("Expected %d, got %d", iterations, counter)
+			}
+		})
+	}
+}
+
+func TestMutexCAS_Backoff_TryLock(t *testing.T) {
+	for name, backoff := range backoffs() {
+		t.Run(name, func(t *testing.T) {
+			mu := NewMutex(WithBackoff(backoff))
+			if !mu.TryLock() {
+				t.Fatal("TryLock should succeed on unlocked mutex")
+			}
+			if mu.TryLock() {
+				t.Error("TryLock should fail on locked mutex")
+			}
+			mu.Unlock()
+		})
+	}
+}
+
+func TestMutexCAS_Backoff_LockContext(t *testing.T) {
+	for name, backoff := range backoffs() {
+		t.Run(name, func(t *testing.T) {
+			mu := NewMutex(WithBackoff(backoff), WithSpinCount(1, 1))
+			mu.Lock()
+			defer mu.Unlock()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+			defer cancel()
+
+			// Бюджет в одну попытку: abort спрашивается на каждой итерации
+			if err := mu.LockContext(ctx); err != context.DeadlineExceeded {
+				t.Errorf("Expected DeadlineExceeded error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestMutexCAS_ExponentialDelayCapped(t *testing.T) {
+	mu := NewMutex(WithBackoff(BackoffExponential))
+	delay := minBackoffDelay
+	for i := 0; i < 20; i++ {
+		delay = mu.pause(delay)
+	}
+	if delay != maxBackoffDelay {
+		t.Errorf("Expected delay capped at %d, got %d", maxBackoffDelay, delay)
+	}
+
+	if got := NewMutex().pause(minBackoffDelay); got != minBackoffDelay {
+		t.Errorf("BackoffNone should not grow delay, got %d", got)
+	}
+}
+
+func TestMutexCAS_Stats(t *testing.T) {
+	mu := NewMutex(WithStats(), WithSpinCount(4, 2))
+	mu.Lock()
+	mu.Unlock()
+	if !mu.TryLock() {
+		t.Fatal("TryLock should succeed on unlocked mutex")
+	}
+
+	acquired := make(chan struct{})
+	go func() {
+		mu.Lock()
+		close(acquired)
+		mu.Unlock()
+	}()
+	time.Sleep(10 * time.Millisecond)
+	mu.Unlock()
+	<-acquired
+
+	s := mu.Stats()
+	if s.Acquisitions != 3 {
+		t.Errorf("Expected 3 acquisitions, got %d", s.Acquisitions)
+	}
+	if s.Contended != 1 || s.FailedAttempts == 0 || s.Yields == 0 {
+		t.Errorf("Expected one contended acquisition with failed CAS and yields, got %+v", s)
+	}
+	if s.WaitTotal < 5*time.Millisecond || s.MaxHold < 5*time.Millisecond {
+		t.Errorf("Expected wait and hold of about 10ms, got wait %v, hold %v", s.WaitTotal, s.MaxHold)
+	}
+}
+
+func TestMutexCAS_StatsDisabled(t *testing.T) {
+	var mu Mutex
+	mu.Lock()
+	mu.Unlock()
+	if s := mu.Stats(); s.Acquisitions != 0 {
+		t.Errorf("Stats should be empty without WithStats, got %+v", s)
+	}
+}
+
+func TestMutexCAS_Conformance_Options(t *testing.T) {
+	for name, backoff := range backoffs() {
+		t.Run(name, func(t *testing.T) {
+			conformance.Run(t, func() mylocker.Locker {
+				return NewMutex(WithBackoff(backoff), WithSpinCount(8, 2), WithStats())
+			})
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package mymutexcas
+
+import (
+	"fmt"
+	"my_concurency/internal/goid"
+	"my_concurency/internal/mylocker"
+	"sync/atomic"
+)
+
+var _ mylocker.Locker = (*RecursiveMutex)(nil)
+
+/*
+RecursiveMutex - реентерабельный мьютекс поверх Mutex: горутина,
+которая уже держит мьютекс, может захватить его снова, и он освобождается
+после стольких же Unlock. Владелец - номер горутины из goid, поэтому
+каждый захват стоит вызова runtime.Stack. Нулевое значение - свободный мьютекс
+*/
+type RecursiveMutex struct {
+	mu    Mutex
+	owner atomic.Int64
+	// глубина захвата, меняет только владелец
+	count int
+}
+
+func (rm *RecursiveMutex) Lock() {
+	me := goid.ID()
+	if rm.owner.Load() == me {
+		rm.count++
+		return
+	}
+
+	rm.mu.Lock()
+	rm.owner.Store(me)
+	rm.count = 1
+}
+
+func (rm *RecursiveMutex) TryLock() bool {
+	me := goid.ID()
+	if rm.owner.Load() == me {
+		rm.count++
+		return true
+	}
+
+	if !rm.mu.TryLock() {
+		return false
+	}
+	rm.owner.Store(me)
+	rm.count = 1
+	return true
+}
+
+// Unlock снимает один уровень захвата, мьютекс освобождается на последнем
+func (rm *RecursiveMutex) Unlock() {
+	me := goid.ID()
+	if owner := rm.owner.Load(); owner != me {
+		if owner == 0 {
+			panic("mymutexcas: unlock of unlocked RecursiveMutex")
+		}
+		panic(fmt.Sprintf("mymutexcas: unlock by goroutine %d of RecursiveMutex held by goroutine %d", me, owner))
+	}
+
+	rm.count--
+	if rm.count == 0 {
+		rm.owner.Store(0)
+		rm.mu.Unlock()
+	}
+}
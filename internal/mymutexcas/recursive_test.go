@@ -0,0 +1,142 @@
+package mymutexcas
+
+import (
+	"my_concurency/internal/conformance"
+	"my_concurency/internal/mylocker"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestRecursiveMutexCAS_NestedLock(t *testing.T) {
+	var rm RecursiveMutex
+	rm.Lock()
+	rm.Lock()
+	if !rm.TryLock() {
+		t.Fatal("TryLock by the owner should succeed")
+	}
+	if rm.count != 3 {
+		t.Errorf("Expected hold count 3, got %d", rm.count)
+	}
+
+	rm.Unlock()
+	rm.Unlock()
+	if rm.mu.TryLock() {
+		t.Fatal("Mutex should stay locked until the last Unlock")
+	}
+
+	rm.Unlock()
+	if !rm.mu.TryLock() {
+		t.Error("Mutex should be released after the last Unlock")
+	}
+	rm.mu.Unlock()
+}
+
+func TestRecursiveMutexCAS_Callbacks(t *testing.T) {
+	var rm RecursiveMutex
+	var calls int
+
+	var visit func(depth int)
+	visit = func(depth int) {
+		rm.Lock()
+		defer rm.Unlock()
+		calls++
+		if depth > 0 {
+			visit(depth - 1)
+		}
+	}
+	visit(10)
+
+	if calls != 11 {
+		t.Errorf("Expected 11 calls, got %d", calls)
+	}
+	if rm.owner.Load() != 0 || rm.count != 0 {
+		t.Errorf("Mutex should be free after all callbacks returned")
+	}
+}
+
+func TestRecursiveMutexCAS_OtherGoroutineBlocked(t *testing.T) {
+	var rm RecursiveMutex
+	rm.Lock()
+	rm.Lock()
+
+	acquired := make(chan bool)
+	go func() {
+		acquired <- rm.TryLock()
+	}()
+	if <-acquired {
+		t.Fatal("TryLock from another goroutine should fail while mutex is held")
+	}
+
+	rm.Unlock()
+	rm.Unlock()
+
+	go func() {
+		ok := rm.TryLock()
+		if ok {
+			rm.Unlock()
+		}
+		acquired <- ok
+	}()
+	if !<-acquired {
+		t.Error("TryLock from another goroutine should succeed after release")
+	}
+}
+
+func TestRecursiveMutexCAS_UnlockByNonOwner(t *testing.T) {
+	var rm RecursiveMutex
+	rm.Lock()
+	defer rm.Unlock()
+
+	var msg string
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		msg = panicMessage(rm.Unlock)
+	}()
+	wg.Wait()
+
+	if !strings.Contains(msg, "held by goroutine") {
+		t.Errorf("Expected panic on unlock by non-owner, got %q", msg)
+	}
+}
+
+func TestRecursiveMutexCAS_UnlockOfUnlocked(t *testing.T) {
+	var rm RecursiveMutex
+	rm.Lock()
+	rm.Unlock()
+
+	msg := panicMessage(rm.Unlock)
+	if !strings.Contains(msg, "unlock of unlocked RecursiveMutex") {
+		t.Errorf("Expected panic on unlock of unlocked mutex, got %q", msg)
+	}
+}
+
+func TestRecursiveMutexCAS_ConcurrentAccess(t *testing.T) {
+	var rm RecursiveMutex
+	var counter int
+	var wg sync.WaitGroup
+	iterations := 1000
+
+	for i := 0; i < iterations; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rm.Lock()
+			rm.Lock()
+			counter++
+			rm.Unlock()
+			rm.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	if counter != iterations {
+		t.Errorf("Expected %d, got %d", iterations, counter)
+	}
+}
+
+func TestRecursiveMutexCAS_Conformance(t *testing.T) {
+	conformance.Run(t, func() mylocker.Locker { return &RecursiveMutex{} })
+}
@@ -0,0 +1,120 @@
+package mymutexcas
+
+import (
+	"my_concurency/internal/mylocker"
+	"runtime"
+	"sync/atomic"
+)
+
+var _ mylocker.RWLocker = (*RWMutex)(nil)
+
+/*
+Все состояние RWMutex в одном атомарном слове, как и у Mutex:
+старший бит - писатель держит мьютекс, следующий - писатель ждет
+(используется только с WriterPreference), младшие биты - число читателей
+*/
+const (
+	rwWriter  = 1 << 31
+	rwWaiting = 1 << 30
+	rwReaders = rwWaiting - 1
+)
+
+/*
+RWMutex - спин-лок читателей/писателей на CompareAndSwap.
+Нулевое значение готово к работе и отдает предпочтение читателям:
+пока есть хоть один читатель, писатель ждет. С WriterPreference
+ожидающий писатель не пускает новых читателей
+*/
+type RWMutex struct {
+	state            atomic.Uint32
+	writerPreference bool
+}
+
+// RWOption настраивает RWMutex, созданный через NewRWMutex
+type RWOption func(*RWMutex)
+
+// WriterPreference: пока писатель ждет, новые читатели не заходят
+func WriterPreference() RWOption {
+	return func(rw *RWMutex) {
+		rw.writerPreference = true
+	}
+}
+
+func NewRWMutex(opts ...RWOption) *RWMutex {
+	rw := &RWMutex{}
+	for _, opt := range opts {
+		opt(rw)
+	}
+	return rw
+}
+
+// Ждем так же, как Mutex.Lock: spinCountLock попыток, потом runtime.Gosched
+func (rw *RWMutex) RLock() {
+	counter := spinCountLock
+	for !rw.TryRLock() {
+		counter--
+		if counter == 0 {
+			runtime.Gosched()
+			counter = spinCountLock
+		}
+	}
+}
+
+// Не получилось только если мьютекс у писателя (или писатель ждет при WriterPreference)
+func (rw *RWMutex) TryRLock() bool {
+	for {
+		state := rw.state.Load()
+		if state&rwWriter != 0 || (rw.writerPreference && state&rwWaiting != 0) {
+			return false
+		}
+		if rw.state.CompareAndSwap(state, state+1) {
+			return true
+		}
+	}
+}
+
+func (rw *RWMutex) RUnlock() {
+	rw.state.Add(^uint32(0))
+}
+
+func (rw *RWMutex) Lock() {
+	counter := spinCountLock
+	for {
+		state := rw.state.Load()
+		if state&(rwWriter|rwReaders) == 0 {
+			/*
+				захватывая, сбрасываем и бит ожидания: остальные
+				ждущие писатели выставят его снова на следующей итерации
+			*/
+			if rw.state.CompareAndSwap(state, rwWriter) {
+				return
+			}
+		} else if rw.writerPreference && state&rwWaiting == 0 {
+			rw.state.CompareAndSwap(state, state|rwWaiting)
+		}
+
+		counter--
+		if counter == 0 {
+			runtime.Gosched()
+			counter = spinCountLock
+		}
+	}
+}
+
+func (rw *RWMutex) TryLock() bool {
+	for {
+		state := rw.state.Load()
+		if state&(rwWriter|rwReaders) != 0 {
+			return false
+		}
+		// CompareAndSwap мог не пройти только из-за бита ожидания
+		if rw.state.CompareAndSwap(state, rwWriter) {
+			return true
+		}
+	}
+}
+
+// Бит ожидания оставляем: его могли выставить, пока мы держали мьютекс
+func (rw *RWMutex) Unlock() {
+	rw.state.Add(^uint32(rwWriter - 1))
+}
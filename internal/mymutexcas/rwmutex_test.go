@@ -0,0 +1,187 @@
+package mymutexcas
+
+import (
+	"my_concurency/internal/conformance"
+	"my_concurency/internal/mylocker"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRWMutexCAS_LockUnlock(t *testing.T) {
+	var rw RWMutex
+	rw.Lock()
+	rw.Unlock()
+	rw.RLock()
+	rw.RUnlock()
+	// Should not panic
+}
+
+func TestRWMutexCAS_ConcurrentAccess(t *testing.T) {
+	var rw RWMutex
+	var counter int
+	var wg sync.WaitGroup
+	iterations := 1000
+
+	for i := 0; i < iterations; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			rw.Lock()
+			counter++
+			rw.Unlock()
+		}()
+		go func() {
+			defer wg.Done()
+			rw.RLock()
+			_ = counter
+			rw.RUnlock()
+		}()
+	}
+	wg.Wait()
+
+	if counter != iterations {
+		t.Errorf("Expected %d, got %d", iterations, counter)
+	}
+}
+
+func TestRWMutexCAS_ReadersShareLock(t *testing.T) {
+	var rw RWMutex
+	const readers = 5
+
+	var inside atomic.Int32
+	var wg sync.WaitGroup
+	allIn := make(chan struct{})
+
+	for i := 0; i < readers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rw.RLock()
+			if inside.Add(1) == readers {
+				close(allIn)
+			}
+			<-allIn
+			rw.RUnlock()
+		}()
+	}
+
+	select {
+	case <-allIn:
+		// Expected
+	case <-time.After(time.Second):
+		t.Fatal("Readers should hold the lock at the same time")
+	}
+	wg.Wait()
+}
+
+func TestRWMutexCAS_WriterExcludesReaders(t *testing.T) {
+	var rw RWMutex
+	rw.Lock()
+
+	if rw.TryRLock() {
+		t.Error("TryRLock should fail while writer holds the lock")
+	}
+	if rw.TryLock() {
+		t.Error("TryLock should fail while writer holds the lock")
+	}
+	rw.Unlock()
+
+	rw.RLock()
+	if rw.TryLock() {
+		t.Error("TryLock should fail while reader holds the lock")
+	}
+	if !rw.TryRLock() {
+		t.Error("TryRLock should succeed while only readers hold the lock")
+	}
+	rw.RUnlock()
+	rw.RUnlock()
+
+	if !rw.TryLock() {
+		t.Error("TryLock should succeed after all readers left")
+	}
+	rw.Unlock()
+}
+
+func TestRWMutexCAS_WriterWaitsForReaders(t *testing.T) {
+	var rw RWMutex
+	rw.RLock()
+
+	locked := make(chan struct{})
+	go func() {
+		rw.Lock()
+		close(locked)
+		rw.Unlock()
+	}()
+
+	select {
+	case <-locked:
+		t.Fatal("Writer should wait for the reader")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	rw.RUnlock()
+
+	select {
+	case <-locked:
+		// Expected
+	case <-time.After(time.Second):
+		t.Fatal("Writer should get the lock after reader left")
+	}
+}
+
+func TestRWMutexCAS_WriterPreference(t *testing.T) {
+	rw := NewRWMutex(WriterPreference())
+	rw.RLock()
+
+	locked := make(chan struct{})
+	go func() {
+		rw.Lock()
+		close(locked)
+		rw.Unlock()
+	}()
+
+	// Ждем, пока писатель выставит бит ожидания
+	for rw.state.Load()&rwWaiting == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	if rw.TryRLock() {
+		t.Error("New readers should not enter while writer is waiting")
+	}
+
+	rw.RUnlock()
+	<-locked
+
+	if !rw.TryRLock() {
+		t.Error("Readers should enter after writer is done")
+	}
+	rw.RUnlock()
+}
+
+func TestRWMutexCAS_ReaderPreferenceByDefault(t *testing.T) {
+	var rw RWMutex
+	rw.RLock()
+
+	go func() {
+		rw.Lock()
+		rw.Unlock()
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	if !rw.TryRLock() {
+		t.Error("Without WriterPreference readers should enter while writer is waiting")
+	}
+	rw.RUnlock()
+	rw.RUnlock()
+}
+
+func TestRWMutexCAS_Conformance(t *testing.T) {
+	t.Run("ReaderPreference", func(t *testing.T) {
+		conformance.Run(t, func() mylocker.Locker { return NewRWMutex() })
+	})
+	t.Run("WriterPreference", func(t *testing.T) {
+		conformance.Run(t, func() mylocker.Locker { return NewRWMutex(WriterPreference()) })
+	})
+}
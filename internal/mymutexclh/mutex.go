@@ -0,0 +1,64 @@
+package mymutexclh
+
+import (
+	"my_concurency/internal/mylocker"
+	"my_concurency/internal/spin"
+	"sync/atomic"
+)
+
+const spinCount = 80
+
+var _ mylocker.Locker = (*Mutex)(nil)
+
+/*
+CLH lock (Craig, Landin, Hagersten). Очередь неявная: каждый ожидающий
+знает только узел предшественника и крутится на его флаге, который
+кроме него никто не читает. Unlock просто снимает флаг своего узла.
+В отличие от MCS, Unlock никогда не ждет следующего
+*/
+type node struct {
+	locked atomic.Bool
+}
+
+type Mutex struct {
+	// последний в очереди, nil или снятый флаг - мьютекс свободен
+	tail atomic.Pointer[node]
+	// узел текущего владельца, пишет и читает его только владелец
+	holder *node
+}
+
+func (mu *Mutex) Lock() {
+	n := &node{}
+	n.locked.Store(true)
+
+	pred := mu.tail.Swap(n)
+	if pred != nil {
+		spin.While(spinCount, pred.locked.Load)
+	}
+	mu.holder = n
+}
+
+/*
+Если последний в очереди уже снял флаг, то очереди на самом деле нет
+и можно встать за ним. Узлы не переиспользуются, так что ABA тут нет
+*/
+func (mu *Mutex) TryLock() bool {
+	tail := mu.tail.Load()
+	if tail != nil && tail.locked.Load() {
+		return false
+	}
+
+	n := &node{}
+	n.locked.Store(true)
+	if !mu.tail.CompareAndSwap(tail, n) {
+		return false
+	}
+	mu.holder = n
+	return true
+}
+
+func (mu *Mutex) Unlock() {
+	n := mu.holder
+	mu.holder = nil
+	n.locked.Store(false)
+}
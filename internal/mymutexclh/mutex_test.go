@@ -0,0 +1,95 @@
+package mymutexclh
+
+import (
+	"my_concurency/internal/conformance"
+	"my_concurency/internal/mylocker"
+	"runtime"
+	"sync"
+	"testing"
+)
+
+func TestMutexCLH_FIFO(t *testing.T) {
+	var mu Mutex
+	mu.Lock()
+
+	order := make(chan int, 5)
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		tail := mu.tail.Load()
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			mu.Lock()
+			order <- i
+			mu.Unlock()
+		}(i)
+		// Дожидаемся, пока горутина встанет в очередь
+		for mu.tail.Load() == tail {
+			runtime.Gosched()
+		}
+	}
+
+	mu.Unlock()
+	wg.Wait()
+	close(order)
+
+	expected := 0
+	for got := range order {
+		if got != expected {
+			t.Errorf("Expected goroutine %d to acquire next, got %d", expected, got)
+		}
+		expected++
+	}
+}
+
+// TryLock не встает в очередь и не обгоняет тех, кто в ней уже стоит
+func TestMutexCLH_TryLockWithQueue(t *testing.T) {
+	var mu Mutex
+	mu.Lock()
+
+	tail := mu.tail.Load()
+	locked := make(chan struct{})
+	go func() {
+		mu.Lock()
+		close(locked)
+		mu.Unlock()
+	}()
+	// Дожидаемся, пока горутина встанет в очередь
+	for mu.tail.Load() == tail {
+		runtime.Gosched()
+	}
+
+	if mu.TryLock() {
+		t.Error("TryLock should fail while the queue is not empty")
+	}
+	mu.Unlock()
+	<-locked
+
+	if !mu.TryLock() {
+		t.Error("TryLock should succeed after the queue drained")
+	}
+	mu.Unlock()
+}
+
+func TestMutexCLH_Conformance(t *testing.T) {
+	conformance.Run(t, func() mylocker.Locker { return &Mutex{} })
+}
+
+// После Unlock tail указывает на снятый узел, и TryLock должен встать за ним
+func TestMutexCLH_TryLockBehindReleasedTail(t *testing.T) {
+	var mu Mutex
+	mu.Lock()
+	mu.Unlock()
+
+	released := mu.tail.Load()
+	if released == nil || released.locked.Load() {
+		t.Fatal("Tail should keep the released node of the last owner")
+	}
+	if !mu.TryLock() {
+		t.Fatal("TryLock should succeed behind a released tail")
+	}
+	if mu.tail.Load() == released {
+		t.Error("TryLock should enqueue its own node")
+	}
+	mu.Unlock()
+}
@@ -0,0 +1,171 @@
+package mymutexhybrid
+
+import (
+	"my_concurency/internal/mylocker"
+	"sync/atomic"
+	"time"
+)
+
+/*
+Состояние, как в sync.Mutex: младшие биты - флаги,
+остальное - число уснувших на семафоре горутин
+*/
+const (
+	mutexLocked = 1 << iota
+	mutexWoken
+	mutexStarving
+	mutexWaiterShift = iota
+
+	// сколько раз крутимся на Load, прежде чем уснуть
+	spinCount = 80
+	/*
+		если горутина ждет дольше, мьютекс переходит в режим голодания:
+		Unlock передает его напрямую первому в очереди, новые горутины
+		не пытаются его перехватить, а сразу встают в конец
+	*/
+	starvationThreshold = time.Millisecond
+)
+
+var _ mylocker.Locker = (*Mutex)(nil)
+
+/*
+Mutex - гибрид спин-лока и sync.Mutex. Быстрый путь тот же, что у
+mymutexcas: один CompareAndSwap. Дальше крутимся spinCount раз, но вместо
+бесконечного runtime.Gosched горутина засыпает на семафоре, и Unlock будит
+ровно одну. Так при коротком удержании получаем задержку спин-лока, а при
+долгом не жжем CPU. Нулевое значение - свободный мьютекс
+*/
+type Mutex struct {
+	state atomic.Int32
+	sema  sema
+}
+
+func (mu *Mutex) Lock() {
+	if mu.state.CompareAndSwap(0, mutexLocked) {
+		return
+	}
+	mu.lockSlow()
+}
+
+func (mu *Mutex) TryLock() bool {
+	old := mu.state.Load()
+	if old&(mutexLocked|mutexStarving) != 0 {
+		return false
+	}
+	return mu.state.CompareAndSwap(old, old|mutexLocked)
+}
+
+func (mu *Mutex) lockSlow() {
+	var waitStart time.Time
+	starving := false
+	awoke := false
+	iter := 0
+	old := mu.state.Load()
+
+	for {
+		// в режиме голодания не крутимся: мьютекс все равно отдадут первому в очереди
+		if old&(mutexLocked|mutexStarving) == mutexLocked && iter < spinCount {
+			/*
+				выставляем mutexWoken, чтобы Unlock не будил спящих,
+				пока мы сами крутимся и готовы захватить мьютекс
+			*/
+			if !awoke && old&mutexWoken == 0 && old>>mutexWaiterShift != 0 &&
+				mu.state.CompareAndSwap(old, old|mutexWoken) {
+				awoke = true
+			}
+			iter++
+			old = mu.state.Load()
+			continue
+		}
+
+		new := old
+		if old&mutexStarving == 0 {
+			new |= mutexLocked
+		}
+		if old&(mutexLocked|mutexStarving) != 0 {
+			new += 1 << mutexWaiterShift
+		}
+		if starving && old&mutexLocked != 0 {
+			new |= mutexStarving
+		}
+		if awoke {
+			if new&mutexWoken == 0 {
+				panic("mymutexhybrid: inconsistent mutex state")
+			}
+			new &^= mutexWoken
+		}
+
+		if !mu.state.CompareAndSwap(old, new) {
+			old = mu.state.Load()
+			continue
+		}
+		if old&(mutexLocked|mutexStarving) == 0 {
+			// захватили CompareAndSwap'ом
+			return
+		}
+
+		// уже ждавшие встают в начало очереди
+		lifo := !waitStart.IsZero()
+		if waitStart.IsZero() {
+			waitStart = time.Now()
+		}
+		mu.sema.acquire(lifo)
+
+		starving = starving || time.Since(waitStart) > starvationThreshold
+		old = mu.state.Load()
+
+		if old&mutexStarving != 0 {
+			// мьютекс передали нам напрямую, он наш, поправляем состояние
+			if old&(mutexLocked|mutexWoken) != 0 || old>>mutexWaiterShift == 0 {
+				panic("mymutexhybrid: inconsistent mutex state")
+			}
+			delta := int32(mutexLocked - 1<<mutexWaiterShift)
+			if !starving || old>>mutexWaiterShift == 1 {
+				// выходим из режима голодания, пока он не затянулся
+				delta -= mutexStarving
+			}
+			mu.state.Add(delta)
+			return
+		}
+
+		awoke = true
+		iter = 0
+	}
+}
+
+func (mu *Mutex) Unlock() {
+	new := mu.state.Add(-mutexLocked)
+	if new != 0 {
+		mu.unlockSlow(new)
+	}
+}
+
+func (mu *Mutex) unlockSlow(new int32) {
+	if (new+mutexLocked)&mutexLocked == 0 {
+		panic("mymutexhybrid: unlock of unlocked mutex")
+	}
+
+	if new&mutexStarving != 0 {
+		// режим голодания: отдаем мьютекс первому в очереди
+		mu.sema.release()
+		return
+	}
+
+	old := new
+	for {
+		/*
+			будить некого, или мьютекс уже кто-то взял,
+			или уже есть проснувшийся, или режим голодания
+		*/
+		if old>>mutexWaiterShift == 0 || old&(mutexLocked|mutexWoken|mutexStarving) != 0 {
+			return
+		}
+
+		new = (old - 1<<mutexWaiterShift) | mutexWoken
+		if mu.state.CompareAndSwap(old, new) {
+			mu.sema.release()
+			return
+		}
+		old = mu.state.Load()
+	}
+}
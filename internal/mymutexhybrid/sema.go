@@ -0,0 +1,51 @@
+package mymutexhybrid
+
+import "my_concurency/internal/mymutexcas"
+
+/*
+Семафор для парковки горутин, замена runtime_Semacquire из sync.
+Каждая уснувшая горутина ждет на своем канале, release будит ровно
+одну, закрывая ее канал. Если release пришел раньше acquire, он
+запоминается в count и следующий acquire не засыпает
+*/
+type sema struct {
+	mu      mymutexcas.Mutex
+	count   int
+	waiters []chan struct{}
+}
+
+// lifo = true ставит в начало очереди: так sync.Mutex поступает с уже ждавшими горутинами
+func (s *sema) acquire(lifo bool) {
+	s.mu.Lock()
+	if s.count > 0 {
+		s.count--
+		s.mu.Unlock()
+		return
+	}
+
+	wake := make(chan struct{})
+	if lifo {
+		s.waiters = append([]chan struct{}{wake}, s.waiters...)
+	} else {
+		s.waiters = append(s.waiters, wake)
+	}
+	s.mu.Unlock()
+
+	<-wake
+}
+
+func (s *sema) release() {
+	s.mu.Lock()
+	if len(s.waiters) == 0 {
+		s.count++
+		s.mu.Unlock()
+		return
+	}
+
+	wake := s.waiters[0]
+	s.waiters[0] = nil
+	s.waiters = s.waiters[1:]
+	s.mu.Unlock()
+
+	close(wake)
+}
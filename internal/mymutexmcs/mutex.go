@@ -0,0 +1,73 @@
+package mymutexmcs
+
+import (
+	"my_concurency/internal/mylocker"
+	"my_concurency/internal/spin"
+	"sync/atomic"
+)
+
+const spinCount = 80
+
+var _ mylocker.Locker = (*Mutex)(nil)
+
+/*
+MCS lock (Mellor-Crummey, Scott). Ожидающие выстраиваются в связный
+список, и каждый крутится на флаге своего собственного узла, а не на
+общем ownerTicket, как в mymutextic. Unlock будит ровно следующего,
+записывая в его узел, поэтому кэш-линия гоняется только между двумя ядрами
+*/
+type node struct {
+	next   atomic.Pointer[node]
+	locked atomic.Bool
+}
+
+type Mutex struct {
+	// последний в очереди, nil - мьютекс свободен
+	tail atomic.Pointer[node]
+	/*
+		узел текущего владельца, чтобы Unlock без аргументов знал,
+		кого будить. Пишет и читает его только владелец
+	*/
+	holder *node
+}
+
+func (mu *Mutex) Lock() {
+	n := &node{}
+	n.locked.Store(true)
+
+	prev := mu.tail.Swap(n)
+	if prev != nil {
+		// встаем за prev и ждем, пока он снимет наш флаг
+		prev.next.Store(n)
+		spin.While(spinCount, n.locked.Load)
+	}
+	mu.holder = n
+}
+
+func (mu *Mutex) TryLock() bool {
+	n := &node{}
+	if !mu.tail.CompareAndSwap(nil, n) {
+		return false
+	}
+	mu.holder = n
+	return true
+}
+
+func (mu *Mutex) Unlock() {
+	n := mu.holder
+	mu.holder = nil
+
+	next := n.next.Load()
+	if next == nil {
+		// за нами никого: освобождаем мьютекс
+		if mu.tail.CompareAndSwap(n, nil) {
+			return
+		}
+		// кто-то уже сделал Swap, но еще не записал себя в n.next
+		spin.While(spinCount, func() bool {
+			next = n.next.Load()
+			return next == nil
+		})
+	}
+	next.locked.Store(false)
+}
@@ -0,0 +1,97 @@
+package mymutexmcs
+
+import (
+	"my_concurency/internal/conformance"
+	"my_concurency/internal/mylocker"
+	"runtime"
+	"sync"
+	"testing"
+)
+
+func TestMutexMCS_FIFO(t *testing.T) {
+	var mu Mutex
+	mu.Lock()
+
+	order := make(chan int, 5)
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		tail := mu.tail.Load()
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			mu.Lock()
+			order <- i
+			mu.Unlock()
+		}(i)
+		// Дожидаемся, пока горутина встанет в очередь
+		for mu.tail.Load() == tail {
+			runtime.Gosched()
+		}
+	}
+
+	mu.Unlock()
+	wg.Wait()
+	close(order)
+
+	expected := 0
+	for got := range order {
+		if got != expected {
+			t.Errorf("Expected goroutine %d to acquire next, got %d", expected, got)
+		}
+		expected++
+	}
+}
+
+// TryLock не встает в очередь и не обгоняет тех, кто в ней уже стоит
+func TestMutexMCS_TryLockWithQueue(t *testing.T) {
+	var mu Mutex
+	mu.Lock()
+
+	tail := mu.tail.Load()
+	locked := make(chan struct{})
+	go func() {
+		mu.Lock()
+		close(locked)
+		mu.Unlock()
+	}()
+	// Дожидаемся, пока горутина встанет в очередь
+	for mu.tail.Load() == tail {
+		runtime.Gosched()
+	}
+
+	if mu.TryLock() {
+		t.Error("TryLock should fail while the queue is not empty")
+	}
+	mu.Unlock()
+	<-locked
+
+	if !mu.TryLock() {
+		t.Error("TryLock should succeed after the queue drained")
+	}
+	mu.Unlock()
+}
+
+func TestMutexMCS_Conformance(t *testing.T) {
+	conformance.Run(t, func() mylocker.Locker { return &Mutex{} })
+}
+
+// Последний из очереди возвращает tail в nil, иначе следующий TryLock не прошел бы
+func TestMutexMCS_UnlockClearsTail(t *testing.T) {
+	var mu Mutex
+	mu.Lock()
+	locked := make(chan struct{})
+	go func() {
+		mu.Lock()
+		close(locked)
+		mu.Unlock()
+	}()
+	for mu.tail.Load() == mu.holder {
+		runtime.Gosched()
+	}
+	mu.Unlock()
+	<-locked
+
+	if mu.tail.Load() != nil {
+		t.Error("Tail should be nil after the queue drained")
+	}
+}
@@ -0,0 +1,112 @@
+package mymutextic
+
+import (
+	"context"
+	"my_concurency/internal/mylocker"
+	"sync"
+	"time"
+)
+
+var _ mylocker.Locker = (*AbortableMutex)(nil)
+
+/*
+AbortableMutex - ticket lock, из очереди которого можно уйти.
+В обычном Mutex взятый билет обязан дойти до владения: ownerTicket
+проходит билеты строго по одному. Здесь ушедший ожидающий оставляет
+на своем билете отметку в abandoned, и Unlock перепрыгивает такие билеты.
+Порядок для оставшихся в очереди по-прежнему FIFO, лишних горутин нет
+*/
+type AbortableMutex struct {
+	ownerTicket atomicInt64
+	nextTicket  atomicInt64
+	// билеты, от которых отказались, ключ - номер билета
+	abandoned sync.Map
+}
+
+func (mu *AbortableMutex) Lock() {
+	ticket := mu.nextTicket.Add(1) - 1
+	waitTicket(&mu.ownerTicket, ticket, waitPolicy{}, nil)
+}
+
+// Мьютекс свободен, когда nextTicket == ownerTicket, тогда забираем билет владельца
+func (mu *AbortableMutex) TryLock() bool {
+	owner := mu.ownerTicket.Load()
+	return mu.nextTicket.CompareAndSwap(owner, owner+1)
+}
+
+/*
+LockContext захватывает мьютекс как Lock, но уходит из очереди, как только
+закроется ctx.Done(), и возвращает ctx.Err(). Если ctx уже отменен,
+в очередь не встаем
+*/
+func (mu *AbortableMutex) LockContext(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	ticket := mu.nextTicket.Add(1) - 1
+	if waitTicket(&mu.ownerTicket, ticket, waitPolicy{}, isClosed(ctx.Done())) {
+		return nil
+	}
+
+	mu.abandon(ticket)
+	return ctx.Err()
+}
+
+// TryLockUntil пытается захватить мьютекс до момента deadline, true если получилось
+func (mu *AbortableMutex) TryLockUntil(deadline time.Time) bool {
+	ticket := mu.nextTicket.Add(1) - 1
+	if waitTicket(&mu.ownerTicket, ticket, waitPolicy{}, isPast(deadline)) {
+		return true
+	}
+
+	mu.abandon(ticket)
+	return false
+}
+
+// TryLockFor пытается захватить мьютекс в течение d
+func (mu *AbortableMutex) TryLockFor(d time.Duration) bool {
+	return mu.TryLockUntil(time.Now().Add(d))
+}
+
+func (mu *AbortableMutex) Unlock() {
+	passTicket(&mu.ownerTicket, &mu.abandoned)
+}
+
+func (mu *AbortableMutex) abandon(ticket int64) {
+	leaveQueue(&mu.ownerTicket, &mu.abandoned, ticket)
+}
+
+/*
+Передает владение следующему билету, пропуская брошенные.
+Отметку забирает LoadAndDelete: и Unlock, и ушедший ожидающий
+пытаются ее забрать, и пропустить билет может только один из них
+*/
+func passTicket(owner *atomicInt64, abandoned *sync.Map) {
+	next := owner.Load() + 1
+	for {
+		owner.Store(next)
+		switchPoint("abandoned.LoadAndDelete")
+		if _, ok := abandoned.LoadAndDelete(next); !ok {
+			return
+		}
+		next++
+	}
+}
+
+/*
+Уход из очереди: ставим отметку на билет. Если owner уже
+дошел до нас, Unlock мог не увидеть отметку - тогда пробуем забрать
+ее сами и, если получилось, мы владельцы и сразу отпускаем мьютекс
+*/
+func leaveQueue(owner *atomicInt64, abandoned *sync.Map, ticket int64) {
+	switchPoint("abandoned.Store")
+	abandoned.Store(ticket, struct{}{})
+
+	if owner.Load() == ticket {
+		switchPoint("abandoned.LoadAndDelete")
+		if _, ok := abandoned.LoadAndDelete(ticket); ok {
+			passTicket(owner, abandoned)
+		}
+	}
+}
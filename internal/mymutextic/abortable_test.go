@@ -0,0 +1,141 @@
+package mymutextic
+
+import (
+	"context"
+	"my_concurency/internal/conformance"
+	"my_concurency/internal/mylocker"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAbortableMutex_AbandonedTicketsAreSkipped(t *testing.T) {
+	var mu AbortableMutex
+	mu.Lock()
+
+	for i := 0; i < 5; i++ {
+		if mu.TryLockFor(time.Millisecond) {
+			t.Fatal("TryLockFor should fail on locked mutex")
+		}
+	}
+
+	mu.Unlock()
+
+	// Все брошенные билеты пропущены синхронно, без вспомогательных горутин
+	if !mu.TryLock() {
+		t.Error("Mutex should be free right after unlock when all waiters left")
+	}
+	mu.Unlock()
+}
+
+func TestAbortableMutex_LockContext(t *testing.T) {
+	var mu AbortableMutex
+	mu.Lock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if err := mu.LockContext(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Expected DeadlineExceeded error, got %v", err)
+	}
+	mu.Unlock()
+
+	if err := mu.LockContext(context.Background()); err != nil {
+		t.Errorf("LockContext should succeed after abandoned waiter, got %v", err)
+	}
+	mu.Unlock()
+}
+
+func TestAbortableMutex_FIFOWithAbandonedWaiters(t *testing.T) {
+	var mu AbortableMutex
+	mu.Lock()
+
+	order := make(chan int, 10)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if i%2 == 1 {
+				// нечетные уходят из очереди
+				if mu.TryLockFor(5 * time.Millisecond) {
+					mu.Unlock()
+				}
+				return
+			}
+			mu.Lock()
+			order <- i
+			mu.Unlock()
+		}(i)
+		// Дожидаемся, пока горутина встанет в очередь
+		for mu.nextTicket.Load() != int64(i+2) {
+			runtime.Gosched()
+		}
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	mu.Unlock()
+	wg.Wait()
+	close(order)
+
+	expected := 0
+	for got := range order {
+		if got != expected {
+			t.Errorf("Expected goroutine %d to acquire next, got %d", expected, got)
+		}
+		expected += 2
+	}
+	if expected != 10 {
+		t.Errorf("Not all waiters acquired the mutex, last expected %d", expected)
+	}
+}
+
+// Брошенные билеты под нагрузкой не нарушают взаимное исключение и не оставляют мьютекс занятым
+func TestAbortableMutex_ConcurrentAbort(t *testing.T) {
+	var mu AbortableMutex
+	var inside, violations, acquired atomic.Int32
+	var wg sync.WaitGroup
+	iterations := 1000
+	before := runtime.NumGoroutine()
+
+	for i := 0; i < iterations; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			timeout := time.Second
+			if i%2 == 0 {
+				timeout = time.Microsecond
+			}
+			if mu.TryLockFor(timeout) {
+				acquired.Add(1)
+				if inside.Add(1) != 1 {
+					violations.Add(1)
+				}
+				runtime.Gosched()
+				inside.Add(-1)
+				mu.Unlock()
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if n := violations.Load(); n != 0 {
+		t.Errorf("Critical section was entered concurrently %d times", n)
+	}
+	if acquired.Load() == 0 {
+		t.Error("At least one TryLockFor should succeed")
+	}
+	if !mu.TryLock() {
+		t.Fatal("Mutex should be free after all abandoned tickets were skipped")
+	}
+	mu.Unlock()
+	if after := runtime.NumGoroutine(); after > before+5 {
+		t.Errorf("Abandoned tickets should not leave goroutines: %d before, %d after", before, after)
+	}
+}
+
+func TestAbortableMutex_Conformance(t *testing.T) {
+	conformance.Run(t, func() mylocker.Locker { return &AbortableMutex{} })
+}
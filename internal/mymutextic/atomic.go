@@ -0,0 +1,14 @@
+//go:build !interleave
+
+package mymutextic
+
+import "sync/atomic"
+
+// Счетчики билетов. Под тегом interleave - инструментированная версия, см. atomic_interleave.go
+type atomicInt64 = atomic.Int64
+
+// Проверок подряд перед первым runtime.Gosched
+const spinCount = 80
+
+// Точка переключения для операций, которые не атомики (sync.Map), вне interleave ничего не делает
+func switchPoint(string) {}
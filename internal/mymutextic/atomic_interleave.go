@@ -0,0 +1,19 @@
+//go:build interleave
+
+package mymutextic
+
+import "my_concurency/internal/interleave"
+
+// Сборка для model checking: каждая операция над билетами - точка переключения планировщика interleave
+type atomicInt64 = interleave.Int64
+
+/*
+Каждая проверка билета здесь - точка выбора, и длинный спин съел бы всю
+глубину перебора до того, как дойдет до интересных мест (уход из очереди)
+*/
+const spinCount = 2
+
+// sync.Map планировщик не видит, поэтому операции над abandoned отмечаем сами
+func switchPoint(op string) {
+	interleave.Yield(op)
+}
@@ -0,0 +1,64 @@
+package mymutextic
+
+import (
+	"fmt"
+	"my_concurency/internal/goid"
+)
+
+/*
+WithOwnerCheck включает режим проверок: мьютекс запоминает горутину-владельца
+и паникует при Unlock свободного мьютекса, при Unlock из чужой горутины и
+при повторном Lock владельцем. Для ticket lock лишний Unlock особенно опасен:
+ownerTicket уходит вперед, и внутрь пускаются сразу две горутины.
+Номер горутины достается через runtime.Stack, так что режим для отладки и тестов
+*/
+func WithOwnerCheck() Option {
+	return func(mu *Mutex) {
+		mu.checked = true
+	}
+}
+
+/*
+Номер текущей горутины, 0 без режима проверок. Достаем его до захвата:
+runtime.Stack не быстрый, и под мьютексом его ждала бы вся очередь
+*/
+func (mu *Mutex) self() int64 {
+	if !mu.checked {
+		return 0
+	}
+	return goid.ID()
+}
+
+func (mu *Mutex) setOwner(me int64) {
+	if mu.checked {
+		mu.owner.Store(me)
+	}
+}
+
+// Возвращает номер текущей горутины для setOwner
+func (mu *Mutex) checkRecursive() int64 {
+	me := mu.self()
+	if mu.checked && mu.owner.Load() == me {
+		panic("mymutextic: recursive Lock by the goroutine that holds the mutex")
+	}
+	return me
+}
+
+/*
+Мьютекс свободен, когда ownerTicket догнал nextTicket или владелец
+еще не записан. Владельца сбрасываем до сдвига ownerTicket,
+чтобы следующий владелец не увидел старого
+*/
+func (mu *Mutex) checkUnlock() {
+	if !mu.checked {
+		return
+	}
+	owner := mu.owner.Load()
+	if owner == 0 || mu.ownerTicket.Load() == mu.nextTicket.Load() {
+		panic("mymutextic: unlock of unlocked mutex")
+	}
+	if me := goid.ID(); owner != me {
+		panic(fmt.Sprintf("mymutextic: unlock by goroutine %d of mutex held by goroutine %d", me, owner))
+	}
+	mu.owner.Store(0)
+}
@@ -0,0 +1,135 @@
+package mymutextic
+
+import (
+	"my_concurency/internal/conformance"
+	"my_concurency/internal/mylocker"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Запускает f и возвращает сообщение паники, пустое, если паники не было
+func panicMessage(f func()) (msg string) {
+	defer func() {
+		if r := recover(); r != nil {
+			msg = r.(string)
+		}
+	}()
+	f()
+	return ""
+}
+
+func TestMutexTIC_Checked_UnlockOfUnlocked(t *testing.T) {
+	mu := NewMutex(WithOwnerCheck())
+	msg := panicMessage(mu.Unlock)
+	if !strings.Contains(msg, "unlock of unlocked mutex") {
+		t.Errorf("Expected panic on unlock of unlocked mutex, got %q", msg)
+	}
+}
+
+func TestMutexTIC_Checked_DoubleUnlock(t *testing.T) {
+	mu := NewMutex(WithOwnerCheck())
+	mu.Lock()
+	mu.Unlock()
+	msg := panicMessage(mu.Unlock)
+	if !strings.Contains(msg, "unlock of unlocked mutex") {
+		t.Errorf("Expected panic on double unlock, got %q", msg)
+	}
+}
+
+func TestMutexTIC_Checked_UnlockByNonOwner(t *testing.T) {
+	mu := NewMutex(WithOwnerCheck())
+	mu.Lock()
+
+	var msg string
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		msg = panicMessage(mu.Unlock)
+	}()
+	wg.Wait()
+
+	if !strings.Contains(msg, "held by goroutine") {
+		t.Errorf("Expected panic on unlock by non-owner, got %q", msg)
+	}
+	// Мьютекс после неудачной попытки остается за владельцем
+	mu.Unlock()
+}
+
+func TestMutexTIC_Checked_RecursiveLock(t *testing.T) {
+	mu := NewMutex(WithOwnerCheck())
+	mu.Lock()
+	defer mu.Unlock()
+
+	msg := panicMessage(mu.Lock)
+	if !strings.Contains(msg, "recursive Lock") {
+		t.Errorf("Expected panic on recursive Lock, got %q", msg)
+	}
+
+	// TryLock владельцем по-прежнему просто возвращает false
+	if mu.TryLock() {
+		t.Error("Mutex should not be reentrant")
+	}
+}
+
+// Проверяется учет владельца, а не время ожидания: TryLock с откатом на Lock, без таймаутов
+func TestMutexTIC_Checked_ConcurrentAccess(t *testing.T) {
+	mu := NewMutex(WithOwnerCheck())
+	var counter int
+	var wg sync.WaitGroup
+	const goroutines, iterations = 20, 50
+
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < iterations; i++ {
+				if g%2 == 0 || !mu.TryLock() {
+					mu.Lock()
+				}
+				counter++
+				mu.Unlock()
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	if expected := goroutines * iterations; counter != expected {
+		t.Errorf("Expected %d, got %d", expected, counter)
+	}
+}
+
+func TestMutexTIC_Unchecked_DoubleUnlockIsSilent(t *testing.T) {
+	var mu Mutex
+	mu.Lock()
+	mu.Unlock()
+	if msg := panicMessage(mu.Unlock); msg != "" {
+		t.Errorf("Zero value mutex should not check ownership, got panic %q", msg)
+	}
+}
+
+func TestMutexTIC_Checked_AbandonedTicket(t *testing.T) {
+	mu := NewMutex(WithOwnerCheck())
+	mu.Lock()
+
+	done := make(chan bool)
+	go func() {
+		done <- mu.TryLockFor(time.Millisecond)
+	}()
+	if <-done {
+		t.Fatal("TryLockFor should fail on locked mutex")
+	}
+	mu.Unlock()
+
+	// Брошенный билет Unlock перепрыгивает, проверка владельца не должна паниковать
+	if !mu.TryLockFor(time.Second) {
+		t.Fatal("TryLockFor should succeed after the abandoned ticket is released")
+	}
+	mu.Unlock()
+}
+
+func TestMutexTIC_Conformance_Checked(t *testing.T) {
+	conformance.Run(t, func() mylocker.Locker { return NewMutex(WithOwnerCheck()) })
+}
@@ -0,0 +1,170 @@
+//go:build interleave
+
+package mymutextic
+
+import (
+	"errors"
+	"my_concurency/internal/interleave"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+/*
+Model checking под тегом interleave: go test -tags interleave ./internal/mymutextic/
+Каждая операция над ownerTicket и nextTicket - точка переключения
+*/
+
+func TestMutexTIC_Interleave_Lock(t *testing.T) {
+	setup := func() ([]func(), func() error) {
+		var mu Mutex
+		var inside atomic.Int32
+		var counter int
+
+		thread := func() {
+			mu.Lock()
+			if inside.Add(1) != 1 {
+				panic("two goroutines in critical section")
+			}
+			// точка переключения внутри критической секции, иначе ее некому нарушить
+			interleave.Yield("critical section")
+			counter++
+			inside.Add(-1)
+			mu.Unlock()
+		}
+		check := func() error {
+			if counter != 3 {
+				return errors.New("lost update")
+			}
+			return nil
+		}
+		return []func(){thread, thread, thread}, check
+	}
+
+	res := interleave.Explore(interleave.Config{Depth: 7}, setup)
+	if res.Failure != nil {
+		t.Fatal(res.Failure)
+	}
+}
+
+/*
+Сценарий гонки старого TryLock: два TryLock одновременно, потом Lock.
+Раньше проигравший TryLock оставлял за собой билет, и Lock висел вечно
+*/
+func TestMutexTIC_Interleave_TryLockRace(t *testing.T) {
+	setup := func() ([]func(), func() error) {
+		var mu Mutex
+		thread := func() {
+			if mu.TryLock() {
+				mu.Unlock()
+			}
+			mu.Lock()
+			mu.Unlock()
+		}
+		check := func() error {
+			if mu.ownerTicket.Load() != mu.nextTicket.Load() {
+				return errors.New("ticket leaked: mutex is left locked")
+			}
+			return nil
+		}
+		return []func(){thread, thread}, check
+	}
+
+	res := interleave.Explore(interleave.Config{Depth: 12, MaxSteps: 2000}, setup)
+	if res.Failure != nil {
+		t.Fatal(res.Failure)
+	}
+	if !res.Complete {
+		t.Errorf("Expected all schedules within depth to be explored, stopped after %d", res.Runs)
+	}
+}
+
+func TestAbortableMutex_Interleave_Lock(t *testing.T) {
+	setup := func() ([]func(), func() error) {
+		var mu AbortableMutex
+		var inside atomic.Int32
+
+		thread := func() {
+			if !mu.TryLock() {
+				mu.Lock()
+			}
+			if inside.Add(1) != 1 {
+				panic("two goroutines in critical section")
+			}
+			// точка переключения внутри критической секции, иначе ее некому нарушить
+			interleave.Yield("critical section")
+			inside.Add(-1)
+			mu.Unlock()
+		}
+		return []func(){thread, thread}, nil
+	}
+
+	res := interleave.Explore(interleave.Config{Depth: 12}, setup)
+	if res.Failure != nil {
+		t.Fatal(res.Failure)
+	}
+}
+
+/*
+Единственная тонкая гонка AbortableMutex: Unlock и abandon забирают
+одну отметку в abandoned. Владелец отпускает мьютекс, пока второй поток
+бросает билет (TryLockUntil с прошедшим дедлайном), а третий стоит за ним.
+Брошенный билет должен быть пропущен ровно один раз.
+Окно гонки лежит глубже, чем Systematic успевает перебрать с тремя потоками,
+поэтому случайные расписания с фиксированным Seed
+*/
+func TestAbortableMutex_Interleave_Abandon(t *testing.T) {
+	setup := func() ([]func(), func() error) {
+		var mu AbortableMutex
+		var inside atomic.Int32
+		var acquired atomic.Int32
+
+		critical := func() {
+			acquired.Add(1)
+			if inside.Add(1) != 1 {
+				panic("two goroutines in critical section")
+			}
+			interleave.Yield("critical section")
+			inside.Add(-1)
+			mu.Unlock()
+		}
+		// держит мьютекс, пока остальные встают в очередь
+		owner := func() {
+			mu.Lock()
+			critical()
+		}
+		// бросает билет, если не успел: дедлайн уже прошел
+		quitter := func() {
+			if mu.TryLockUntil(time.Now().Add(-time.Second)) {
+				critical()
+			}
+		}
+		waiter := func() {
+			mu.Lock()
+			critical()
+		}
+		check := func() error {
+			if mu.ownerTicket.Load() != mu.nextTicket.Load() {
+				return errors.New("mutex is left locked: abandoned ticket was not skipped")
+			}
+			if acquired.Load() < 2 {
+				return errors.New("lock holders are missing")
+			}
+			empty := true
+			mu.abandoned.Range(func(_, _ any) bool {
+				empty = false
+				return false
+			})
+			if !empty {
+				return errors.New("abandoned mark was not consumed")
+			}
+			return nil
+		}
+		return []func(){owner, quitter, waiter}, check
+	}
+
+	res := interleave.Explore(interleave.Config{Mode: interleave.Random, Runs: 5000, Seed: 1, MaxSteps: 5000}, setup)
+	if res.Failure != nil {
+		t.Fatal(res.Failure)
+	}
+}
@@ -1,19 +1,33 @@
 package mymutextic
 
 import (
+	"context"
+	"my_concurency/internal/mylocker"
 	"runtime"
+	"sync"
 	"sync/atomic"
+	"time"
 )
 
+// spinCount задан в atomic.go и atomic_interleave.go
 const (
-	locked    = true
-	unlocked  = false
-	spinCount = 80
+	locked   = true
+	unlocked = false
 )
 
+var _ mylocker.Locker = (*Mutex)(nil)
+
+// Нулевое значение - свободный мьютекс, настройки ожидания можно поменять через NewMutex
 type Mutex struct {
-	ownerTicket atomic.Int64
-	nextTicket  atomic.Int64
+	ownerTicket atomicInt64
+	nextTicket  atomicInt64
+	policy      waitPolicy
+	// брошенные билеты LockContext и TryLockUntil, как в AbortableMutex
+	abandoned sync.Map
+
+	// режим проверок WithOwnerCheck, owner - номер горутины-владельца или 0
+	checked bool
+	owner   atomic.Int64
 }
 
 /*
@@ -26,36 +40,147 @@ type Mutex struct {
 */
 
 func (mu *Mutex) Lock() {
+	// проверяем до того, как взять билет, иначе после паники очередь встанет
+	me := mu.checkRecursive()
 	// получаем текущий билет и задаем в очереди следующий
 	ticket := mu.nextTicket.Add(1) - 1
+	waitTicket(&mu.ownerTicket, ticket, mu.policy, nil)
+	mu.setOwner(me)
+}
+
+/*
+LockContext захватывает мьютекс как Lock, но уходит из очереди, как только
+закроется ctx.Done(), и возвращает ctx.Err(). Принимает и mycontext.Context,
+и контекст из стандартной библиотеки. Если ctx уже отменен, мьютекс не захватывается
+*/
+func (mu *Mutex) LockContext(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	me := mu.checkRecursive()
+
+	ticket := mu.nextTicket.Add(1) - 1
+	if waitTicket(&mu.ownerTicket, ticket, mu.policy, isClosed(ctx.Done())) {
+		mu.setOwner(me)
+		return nil
+	}
+
+	mu.abandonTicket(ticket)
+	return ctx.Err()
+}
+
+/*
+TryLockUntil пытается захватить мьютекс до момента deadline,
+ожидая так же, как Lock. Возвращает true, если мьютекс захвачен.
+Если время вышло, из очереди уходим так же, как в LockContext
+*/
+func (mu *Mutex) TryLockUntil(deadline time.Time) bool {
+	me := mu.checkRecursive()
+	ticket := mu.nextTicket.Add(1) - 1
+	if !waitTicket(&mu.ownerTicket, ticket, mu.policy, isPast(deadline)) {
+		mu.abandonTicket(ticket)
+		return false
+	}
+	mu.setOwner(me)
+	return true
+}
+
+// TryLockFor пытается захватить мьютекс в течение d
+func (mu *Mutex) TryLockFor(d time.Duration) bool {
+	return mu.TryLockUntil(time.Now().Add(d))
+}
+
+/*
+Ждет, пока owner дойдет до ticket. Если abort не nil,
+спрашиваем его перед каждым runtime.Gosched: true - перестаем ждать
+и возвращаем false, билет при этом остается за нами
+*/
+func waitTicket(owner *atomicInt64, ticket int64, p waitPolicy, abort func() bool) bool {
+	var start time.Time
+	if p.stats != nil {
+		start = time.Now()
+	}
+	var checks, yields uint64
 
-	for i := 0; i < spinCount; i++ {
-		if mu.ownerTicket.Load() == ticket {
+	budget := p.spinBudget()
+	for i := 0; i < budget; i++ {
+		if p.turn(ticket - owner.Load()) {
 			// мьютекс захвачен!
-			return
+			p.acquired(start, checks, yields)
+			return true
 		}
+		checks++
 	}
 
 	// Если не получилось за spinCount попыток, переводим в runable
-	for mu.ownerTicket.Load() != ticket {
+	for !p.turn(ticket - owner.Load()) {
+		checks++
+		if abort != nil && abort() {
+			if p.stats != nil {
+				p.stats.Failed(checks, yields)
+			}
+			return false
+		}
 		runtime.Gosched()
+		yields++
+	}
+	p.acquired(start, checks, yields)
+	return true
+}
+
+// abort для waitTicket: сдаемся, когда закрыт done
+func isClosed(done <-chan struct{}) func() bool {
+	return func() bool {
+		select {
+		case <-done:
+			return true
+		default:
+			return false
+		}
 	}
+}
 
+// abort для waitTicket: сдаемся, когда наступил deadline
+func isPast(deadline time.Time) func() bool {
+	return func() bool {
+		return !time.Now().Before(deadline)
+	}
+}
+
+/*
+Взятый билет просто выбросить нельзя: ownerTicket до него все равно дойдет,
+и все, кто стоит за нами, будут ждать вечно. Поэтому билет отмечается
+брошенным, и Unlock его перепрыгивает, как в AbortableMutex
+*/
+func (mu *Mutex) abandonTicket(ticket int64) {
+	leaveQueue(&mu.ownerTicket, &mu.abandoned, ticket)
 }
 
 /*
-в оргинале он менее конкуретный, здесь это реализовать тяжело так как
-имеет много оптимизаций внутри, поэтому мой TryLock это почти самый обычный Lock
+Раньше тут был Add(1) после проверки, и если между проверкой и Add
+кто-то успевал взять билет, TryLock возвращал false, но билет оставался
+за нами и вся очередь вставала навсегда. Теперь билет берется только
+через CompareAndSwap: мьютекс свободен, когда nextTicket == ownerTicket,
+и тогда забираем именно билет владельца
 */
 func (mu *Mutex) TryLock() bool {
-	if mu.nextTicket.Load() != mu.ownerTicket.Load() {
+	me := mu.self()
+	owner := mu.ownerTicket.Load()
+	if !mu.nextTicket.CompareAndSwap(owner, owner+1) {
+		if mu.policy.stats != nil {
+			mu.policy.stats.Failed(1, 0)
+		}
 		return false
 	}
-
-	ticket := mu.nextTicket.Add(1) - 1
-	return mu.ownerTicket.Load() == ticket
+	mu.setOwner(me)
+	mu.policy.acquired(time.Time{}, 0, 0)
+	return true
 }
 
 func (mu *Mutex) Unlock() {
-	mu.ownerTicket.Add(1)
+	mu.checkUnlock()
+	if mu.policy.stats != nil {
+		mu.policy.stats.Released()
+	}
+	passTicket(&mu.ownerTicket, &mu.abandoned)
 }
@@ -1,112 +1,121 @@
 package mymutextic
 
 import (
+	"my_concurency/internal/conformance"
+	"my_concurency/internal/fairness"
+	"my_concurency/internal/mylocker"
+	"runtime"
 	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
-func TestMutexTIC_LockUnlock(t *testing.T) {
+/*
+Отчет только печатается: на коротком прогоне и Jain, и серии повторных
+захватов зависят от планировщика не меньше, чем от мьютекса.
+Порядок передачи проверяет TestMutexTIC_FIFO
+*/
+func TestMutexTIC_FairnessReport(t *testing.T) {
 	var mu Mutex
-	mu.Lock()
-	mu.Unlock()
-	// Should not panic
+	report := fairness.Run(&mu, fairness.Config{
+		Goroutines: 4,
+		Duration:   50 * time.Millisecond,
+		Work:       100,
+		Yield:      true,
+	})
+	t.Log(report)
+
+	if report.Total == 0 {
+		t.Error("Goroutines should acquire the mutex")
+	}
 }
 
-func TestMutexTIC_ConcurrentAccess(t *testing.T) {
+// Ожидающие получают мьютекс строго в порядке билетов
+func TestMutexTIC_FIFO(t *testing.T) {
 	var mu Mutex
-	var counter int
-	var wg sync.WaitGroup
-	iterations := 1000
+	mu.Lock()
 
-	for i := 0; i < iterations; i++ {
+	order := make(chan int, 5)
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
 		wg.Add(1)
-		go func() {
+		go func(i int) {
 			defer wg.Done()
 			mu.Lock()
-			counter++
+			order <- i
 			mu.Unlock()
-		}()
-	}
-	wg.Wait()
-
-	if counter != iterations {
-		t.Errorf("Expected %d, got %d", iterations, counter)
-	}
-}
-
-func TestMutexTIC_TryLockSuccess(t *testing.T) {
-	var mu Mutex
-	if !mu.TryLock() {
-		t.Error("TryLock should succeed on unlocked mutex")
-	}
-	mu.Unlock()
-}
-
-func TestMutexTIC_TryLockFailure(t *testing.T) {
-	var mu Mutex
-	mu.Lock()
-
-	// TryLock should fail when mutex is already locked
-	if mu.TryLock() {
-		t.Error("TryLock should fail on locked mutex")
+		}(i)
+		// Дожидаемся, пока горутина возьмет свой билет
+		for mu.nextTicket.Load() != int64(i+2) {
+			runtime.Gosched()
+		}
 	}
 
 	mu.Unlock()
-}
-
-func TestMutexTIC_TryLockAfterUnlock(t *testing.T) {
-	var mu Mutex
-	mu.Lock()
-	mu.Unlock()
+	wg.Wait()
+	close(order)
 
-	if !mu.TryLock() {
-		t.Error("TryLock should succeed after unlock")
+	expected := 0
+	for got := range order {
+		if got != expected {
+			t.Errorf("Expected goroutine %d to acquire next, got %d", expected, got)
+		}
+		expected++
 	}
-	mu.Unlock()
 }
 
-func TestMutexTIC_Fairness(t *testing.T) {
+/*
+Раньше проигравший гонку TryLock оставлял за собой билет, и Lock вставал навсегда.
+Точное чередование перебирает TestMutexTIC_Interleave_TryLockRace (тег interleave),
+здесь то же на живом планировщике: несколько горутин и щедрый предел на зависание
+*/
+func TestMutexTIC_TryLockRace(t *testing.T) {
+	const iterations = 500
 	var mu Mutex
+	var counter int
+	var expected atomic.Int64
 	var wg sync.WaitGroup
-	order := make(chan int, 3)
-
-	// First goroutine
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		mu.Lock()
-		order <- 1
-		mu.Unlock()
-	}()
 
-	// Second goroutine
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		mu.Lock()
-		order <- 2
-		mu.Unlock()
-	}()
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < iterations; i++ {
+				if g%2 == 0 {
+					if !mu.TryLock() {
+						continue
+					}
+				} else {
+					mu.Lock()
+				}
+				expected.Add(1)
+				counter++
+				mu.Unlock()
+			}
+		}(g)
+	}
 
-	// Third goroutine
-	wg.Add(1)
+	done := make(chan struct{})
 	go func() {
-		defer wg.Done()
-		mu.Lock()
-		order <- 3
-		mu.Unlock()
+		wg.Wait()
+		close(done)
 	}()
 
-	wg.Wait()
-	close(order)
-
-	// Check that all goroutines executed
-	results := make([]int, 0)
-	for result := range order {
-		results = append(results, result)
+	select {
+	case <-done:
+	case <-time.After(30 * time.Second):
+		t.Fatal("TryLock left a dangling ticket, Lock deadlocked")
 	}
-
-	if len(results) != 3 {
-		t.Errorf("Expected 3 results, got %d", len(results))
+	if int64(counter) != expected.Load() {
+		t.Errorf("Expected %d, got %d", expected.Load(), counter)
+	}
+	if !mu.TryLock() {
+		t.Fatal("Mutex should be free after all goroutines finished")
 	}
+	mu.Unlock()
+}
+
+func TestMutexTIC_Conformance(t *testing.T) {
+	conformance.Run(t, func() mylocker.Locker { return &Mutex{} })
 }
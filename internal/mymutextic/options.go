@@ -0,0 +1,103 @@
+package mymutextic
+
+import (
+	"my_concurency/internal/lockstats"
+	"my_concurency/internal/spin"
+	"time"
+)
+
+// Пауза пропорциональной задержки не длиннее этого числа итераций
+const maxBackoffDelay = 1 << 14
+
+/*
+Настройки ожидания билета. Нулевое значение - прежнее поведение:
+spinCount проверок подряд, потом runtime.Gosched между проверками
+*/
+type waitPolicy struct {
+	// 0 - spinCount по умолчанию
+	spinCount int
+	// итераций паузы на каждый билет перед нами, 0 - без задержки
+	backoffUnit int
+	// статистика WithStats, nil - выключена
+	stats *lockstats.Recorder
+}
+
+// Option настраивает Mutex, созданный через NewMutex
+type Option func(*Mutex)
+
+// WithSpinCount задает число проверок билета до перехода на runtime.Gosched
+func WithSpinCount(n int) Option {
+	return func(mu *Mutex) {
+		mu.policy.spinCount = n
+	}
+}
+
+/*
+WithProportionalBackoff включает пропорциональную задержку: между проверками
+ждем unit итераций на каждый билет перед нами. Ожидающий далеко в очереди
+реже читает ownerTicket, и Unlock меньше бьет по кешам всех ожидающих
+*/
+func WithProportionalBackoff(unit int) Option {
+	return func(mu *Mutex) {
+		mu.policy.backoffUnit = unit
+	}
+}
+
+/*
+WithStats включает статистику: захваты, проверки билета, runtime.Gosched,
+гистограмма ожидания и самое долгое удержание. Снимок отдает Stats
+*/
+func WithStats() Option {
+	return func(mu *Mutex) {
+		mu.policy.stats = lockstats.NewRecorder()
+	}
+}
+
+func NewMutex(opts ...Option) *Mutex {
+	mu := &Mutex{}
+	for _, opt := range opts {
+		opt(mu)
+	}
+	return mu
+}
+
+// Stats возвращает снимок статистики, без WithStats - нулевой
+func (mu *Mutex) Stats() lockstats.Stats {
+	if mu.policy.stats == nil {
+		return lockstats.Stats{}
+	}
+	return mu.policy.stats.Snapshot()
+}
+
+// Учет захвата, start нулевой - захват без ожидания
+func (p waitPolicy) acquired(start time.Time, checks, yields uint64) {
+	if p.stats == nil {
+		return
+	}
+	var wait time.Duration
+	if !start.IsZero() {
+		wait = time.Since(start)
+	}
+	p.stats.Acquired(wait, checks, yields)
+}
+
+func (p waitPolicy) spinBudget() int {
+	if p.spinCount > 0 {
+		return p.spinCount
+	}
+	return spinCount
+}
+
+/*
+ahead - сколько билетов перед нами. true, если очередь дошла до нас,
+иначе при включенной задержке выдерживаем паузу пропорционально ahead
+*/
+func (p waitPolicy) turn(ahead int64) bool {
+	if ahead == 0 {
+		return true
+	}
+	if p.backoffUnit > 0 {
+		spin.Pause(int(min(ahead*int64(p.backoffUnit), maxBackoffDelay)))
+	}
+	return false
+}
@@ -0,0 +1,116 @@
+package mymutextic
+
+import (
+	"my_concurency/internal/conformance"
+	"my_concurency/internal/mylocker"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMutexTIC_NewMutexDefaults(t *testing.T) {
+	mu := NewMutex()
+	if mu.policy != (waitPolicy{}) {
+		t.Errorf("NewMutex without options should behave like the zero value, got %+v", mu.policy)
+	}
+	if budget := mu.policy.spinBudget(); budget != spinCount {
+		t.Errorf("Expected default spin budget %d, got %d", spinCount, budget)
+	}
+}
+
+func TestMutexTIC_ProportionalBackoff_ConcurrentAccess(t *testing.T) {
+	mu := NewMutex(WithProportionalBackoff(32), WithSpinCount(8))
+	var counter int
+	var wg sync.WaitGroup
+	iterations := 1000
+
+	for i := 0; i < iterations; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			mu.Lock()
+			counter++
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	if counter != iterations {
+		t.Errorf("Expected %d, got %d", iterations, counter)
+	}
+}
+
+func TestMutexTIC_ProportionalBackoff_TryLockFor(t *testing.T) {
+	mu := NewMutex(WithProportionalBackoff(1 << 20))
+	mu.Lock()
+
+	// Огромный unit упирается в maxBackoffDelay и не мешает уложиться в срок
+	start := time.Now()
+	if mu.TryLockFor(10 * time.Millisecond) {
+		t.Fatal("TryLockFor should fail on locked mutex")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("TryLockFor took too long: %v", elapsed)
+	}
+	mu.Unlock()
+
+	if !mu.TryLockFor(time.Second) {
+		t.Error("TryLockFor should succeed after the abandoned ticket is released")
+	}
+	mu.Unlock()
+}
+
+func TestMutexTIC_Turn(t *testing.T) {
+	p := waitPolicy{backoffUnit: 4}
+	if !p.turn(0) {
+		t.Error("turn should report our ticket")
+	}
+	if p.turn(3) {
+		t.Error("turn should wait while tickets are ahead")
+	}
+}
+
+func TestMutexTIC_Stats(t *testing.T) {
+	mu := NewMutex(WithStats(), WithSpinCount(4))
+	mu.Lock()
+	mu.Unlock()
+	if !mu.TryLock() {
+		t.Fatal("TryLock should succeed on unlocked mutex")
+	}
+
+	acquired := make(chan struct{})
+	go func() {
+		mu.Lock()
+		close(acquired)
+		mu.Unlock()
+	}()
+	time.Sleep(10 * time.Millisecond)
+	mu.Unlock()
+	<-acquired
+
+	s := mu.Stats()
+	if s.Acquisitions != 3 {
+		t.Errorf("Expected 3 acquisitions, got %d", s.Acquisitions)
+	}
+	if s.Contended != 1 || s.FailedAttempts == 0 || s.Yields == 0 {
+		t.Errorf("Expected one contended acquisition with ticket checks and yields, got %+v", s)
+	}
+	if s.WaitTotal < 5*time.Millisecond || s.MaxHold < 5*time.Millisecond {
+		t.Errorf("Expected wait and hold of about 10ms, got wait %v, hold %v", s.WaitTotal, s.MaxHold)
+	}
+}
+
+func TestMutexTIC_StatsDisabled(t *testing.T) {
+	var mu Mutex
+	mu.Lock()
+	mu.Unlock()
+	if s := mu.Stats(); s.Acquisitions != 0 {
+		t.Errorf("Stats should be empty without WithStats, got %+v", s)
+	}
+}
+
+func TestMutexTIC_Conformance_Options(t *testing.T) {
+	conformance.Run(t, func() mylocker.Locker {
+		return NewMutex(WithProportionalBackoff(16), WithSpinCount(8), WithStats())
+	})
+}
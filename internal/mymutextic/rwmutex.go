@@ -0,0 +1,150 @@
+package mymutextic
+
+import (
+	"my_concurency/internal/mylocker"
+	"my_concurency/internal/spin"
+	"sync/atomic"
+)
+
+var _ mylocker.RWLocker = (*RWMutex)(nil)
+
+/*
+Phase-fair ticket lock (Brandenburg, Anderson). Читатели и писатели
+сменяют друг друга фазами: пришедший писатель закрывает вход новым
+читателям и ждет ушедших, а после его Unlock заходят все читатели,
+которые накопились за это время, даже если ждут другие писатели.
+Так ни читатели, ни писатели не голодают. Это поведение нулевого значения.
+
+С WriterPreference читатель не входит, пока в очереди есть хоть один писатель,
+и накопившиеся читатели пропускают вперед всех ждущих писателей. Под потоком
+писателей читатели при этом могут голодать.
+
+rin/rout - сколько читателей вошло и вышло (шагом rinc), в младших
+битах rin писатель отмечает свое присутствие и номер фазы
+*/
+const (
+	rinc  = 0x100 // один читатель в rin/rout
+	wbits = 0x3   // биты писателя в rin
+	pres  = 0x2   // писатель есть
+	phid  = 0x1   // номер фазы писателя
+)
+
+// RWMutex - честный phase-fair спин-лок читателей/писателей на билетах
+type RWMutex struct {
+	rin, rout atomic.Uint32
+	// билеты писателей, как в Mutex
+	win, wout        atomic.Uint32
+	writerPreference bool
+}
+
+// RWOption настраивает RWMutex, созданный через NewRWMutex
+type RWOption func(*RWMutex)
+
+// WriterPreference: пока писатели стоят в очереди, новые читатели не заходят
+func WriterPreference() RWOption {
+	return func(rw *RWMutex) {
+		rw.writerPreference = true
+	}
+}
+
+func NewRWMutex(opts ...RWOption) *RWMutex {
+	rw := &RWMutex{}
+	for _, opt := range opts {
+		opt(rw)
+	}
+	return rw
+}
+
+// Очередь писателей пуста: билетов выдано столько же, сколько отпущено
+func (rw *RWMutex) noWriters() bool {
+	return rw.win.Load() == rw.wout.Load()
+}
+
+func (rw *RWMutex) RLock() {
+	/*
+		С WriterPreference ждем очередь писателей до того, как войти в rin:
+		вошедшего читателя писатель уже ждет, и ждать писателя в ответ нельзя.
+		Писатель, пришедший после проверки, дальше обслуживается по фазам
+	*/
+	if rw.writerPreference {
+		spin.While(spinCount, func() bool {
+			return !rw.noWriters()
+		})
+	}
+
+	writer := (rw.rin.Add(rinc) - rinc) & wbits
+	if writer == 0 {
+		return
+	}
+
+	// писатель уже здесь, ждем пока его фаза закончится
+	spin.While(spinCount, func() bool {
+		return rw.rin.Load()&wbits == writer
+	})
+}
+
+// Читатель заходит, только если писателя нет, билет не берем, поэтому можно не ждать
+func (rw *RWMutex) TryRLock() bool {
+	if rw.writerPreference && !rw.noWriters() {
+		return false
+	}
+	for {
+		in := rw.rin.Load()
+		if in&wbits != 0 {
+			return false
+		}
+		if rw.rin.CompareAndSwap(in, in+rinc) {
+			return true
+		}
+	}
+}
+
+func (rw *RWMutex) RUnlock() {
+	rw.rout.Add(rinc)
+}
+
+func (rw *RWMutex) Lock() {
+	ticket := rw.win.Add(1) - 1
+	spin.While(spinCount, func() bool {
+		return rw.wout.Load() != ticket
+	})
+
+	// закрываем вход читателям и ждем тех, кто успел войти
+	writer := pres | (ticket & phid)
+	readers := rw.rin.Add(writer) - writer
+	spin.While(spinCount, func() bool {
+		return rw.rout.Load() != readers
+	})
+}
+
+/*
+Берем билет писателя только если он первый в очереди и внутри никого нет.
+Если между проверкой и захватом успел войти читатель, билет отдаем
+дальше так же, как Unlock, ведь rin мы еще не трогали
+*/
+func (rw *RWMutex) TryLock() bool {
+	ticket := rw.wout.Load()
+	if rw.win.Load() != ticket {
+		return false
+	}
+
+	in := rw.rin.Load()
+	if in&wbits != 0 || rw.rout.Load() != in {
+		return false
+	}
+	if !rw.win.CompareAndSwap(ticket, ticket+1) {
+		return false
+	}
+
+	if rw.rin.CompareAndSwap(in, in|pres|(ticket&phid)) {
+		return true
+	}
+	rw.wout.Add(1)
+	return false
+}
+
+func (rw *RWMutex) Unlock() {
+	writer := pres | (rw.wout.Load() & phid)
+	rw.rin.Add(^(writer - 1))
+	rw.wout.Add(1)
+}
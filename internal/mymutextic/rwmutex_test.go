@@ -0,0 +1,254 @@
+package mymutextic
+
+import (
+	"my_concurency/internal/conformance"
+	"my_concurency/internal/mylocker"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRWMutexTIC_LockUnlock(t *testing.T) {
+	var rw RWMutex
+	rw.Lock()
+	rw.Unlock()
+	rw.RLock()
+	rw.RUnlock()
+	// Should not panic
+}
+
+func TestRWMutexTIC_ConcurrentAccess(t *testing.T) {
+	var rw RWMutex
+	var counter int
+	var wg sync.WaitGroup
+	iterations := 1000
+
+	for i := 0; i < iterations; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			rw.Lock()
+			counter++
+			rw.Unlock()
+		}()
+		go func() {
+			defer wg.Done()
+			rw.RLock()
+			_ = counter
+			rw.RUnlock()
+		}()
+	}
+	wg.Wait()
+
+	if counter != iterations {
+		t.Errorf("Expected %d, got %d", iterations, counter)
+	}
+}
+
+func TestRWMutexTIC_ReadersShareLock(t *testing.T) {
+	var rw RWMutex
+	const readers = 5
+
+	var inside atomic.Int32
+	var wg sync.WaitGroup
+	allIn := make(chan struct{})
+
+	for i := 0; i < readers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rw.RLock()
+			if inside.Add(1) == readers {
+				close(allIn)
+			}
+			<-allIn
+			rw.RUnlock()
+		}()
+	}
+
+	select {
+	case <-allIn:
+		// Expected
+	case <-time.After(time.Second):
+		t.Fatal("Readers should hold the lock at the same time")
+	}
+	wg.Wait()
+}
+
+func TestRWMutexTIC_WriterExcludesReaders(t *testing.T) {
+	var rw RWMutex
+	rw.Lock()
+
+	if rw.TryRLock() {
+		t.Error("TryRLock should fail while writer holds the lock")
+	}
+	if rw.TryLock() {
+		t.Error("TryLock should fail while writer holds the lock")
+	}
+	rw.Unlock()
+
+	rw.RLock()
+	if rw.TryLock() {
+		t.Error("TryLock should fail while reader holds the lock")
+	}
+	if !rw.TryRLock() {
+		t.Error("TryRLock should succeed while only readers hold the lock")
+	}
+	rw.RUnlock()
+	rw.RUnlock()
+
+	if !rw.TryLock() {
+		t.Error("TryLock should succeed after all readers left")
+	}
+	rw.Unlock()
+}
+
+func TestRWMutexTIC_WriterWaitsForReaders(t *testing.T) {
+	var rw RWMutex
+	rw.RLock()
+
+	locked := make(chan struct{})
+	go func() {
+		rw.Lock()
+		close(locked)
+		rw.Unlock()
+	}()
+
+	select {
+	case <-locked:
+		t.Fatal("Writer should wait for the reader")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	rw.RUnlock()
+
+	select {
+	case <-locked:
+		// Expected
+	case <-time.After(time.Second):
+		t.Fatal("Writer should get the lock after reader left")
+	}
+}
+
+func TestRWMutexTIC_PhaseFair(t *testing.T) {
+	var rw RWMutex
+	rw.RLock()
+
+	locked := make(chan struct{})
+	go func() {
+		rw.Lock()
+		close(locked)
+		rw.Unlock()
+	}()
+
+	// Ждем, пока писатель закроет вход читателям
+	for rw.rin.Load()&wbits == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// Новый читатель встает за писателем, а не обгоняет его
+	readerIn := make(chan struct{})
+	go func() {
+		rw.RLock()
+		close(readerIn)
+		rw.RUnlock()
+	}()
+
+	select {
+	case <-readerIn:
+		t.Fatal("Reader should not overtake waiting writer")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	rw.RUnlock()
+	<-locked
+	<-readerIn
+}
+
+func TestRWMutexTIC_WriterNotStarved(t *testing.T) {
+	var rw RWMutex
+	var stop atomic.Bool
+	var wg sync.WaitGroup
+
+	// Читатели постоянно перекрывают друг друга
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for !stop.Load() {
+				rw.RLock()
+				time.Sleep(100 * time.Microsecond)
+				rw.RUnlock()
+			}
+		}()
+	}
+
+	locked := make(chan struct{})
+	go func() {
+		rw.Lock()
+		close(locked)
+		rw.Unlock()
+	}()
+
+	select {
+	case <-locked:
+		// Expected
+	case <-time.After(time.Second):
+		t.Error("Writer should not starve behind a stream of readers")
+	}
+	stop.Store(true)
+	wg.Wait()
+}
+
+func TestRWMutexTIC_WriterPreference(t *testing.T) {
+	rw := NewRWMutex(WriterPreference())
+	rw.Lock()
+
+	order := make(chan string, 2)
+	writerDone := make(chan struct{})
+	go func() {
+		rw.Lock()
+		order <- "writer"
+		rw.Unlock()
+		close(writerDone)
+	}()
+
+	// Ждем, пока второй писатель встанет в очередь
+	for rw.win.Load() != 2 {
+		time.Sleep(time.Millisecond)
+	}
+	if rw.TryRLock() {
+		t.Fatal("New readers should not enter while writers are queued")
+	}
+
+	// Без WriterPreference читатель вошел бы между фазами двух писателей
+	readerDone := make(chan struct{})
+	go func() {
+		rw.RLock()
+		order <- "reader"
+		rw.RUnlock()
+		close(readerDone)
+	}()
+
+	rw.Unlock()
+	<-writerDone
+	<-readerDone
+	if first := <-order; first != "writer" {
+		t.Errorf("Queued writer should go before the reader, got %s first", first)
+	}
+
+	if !rw.TryRLock() {
+		t.Error("Readers should enter after writers are done")
+	}
+	rw.RUnlock()
+}
+
+func TestRWMutexTIC_Conformance(t *testing.T) {
+	t.Run("PhaseFair", func(t *testing.T) {
+		conformance.Run(t, func() mylocker.Locker { return NewRWMutex() })
+	})
+	t.Run("WriterPreference", func(t *testing.T) {
+		conformance.Run(t, func() mylocker.Locker { return NewRWMutex(WriterPreference()) })
+	})
+}
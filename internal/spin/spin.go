@@ -0,0 +1,25 @@
+package spin
+
+import "runtime"
+
+/*
+While ждет, пока cond не вернет false: n проверок подряд, потом
+runtime.Gosched между проверками. Так же ждут Lock у mymutextic,
+mymutexmcs и mymutexclh
+*/
+func While(n int, cond func() bool) {
+	for i := 0; i < n; i++ {
+		if !cond() {
+			return
+		}
+	}
+	for cond() {
+		runtime.Gosched()
+	}
+}
+
+// Pause - пустой цикл на n итераций: ждем, не трогая общую память
+func Pause(n int) {
+	for i := 0; i < n; i++ {
+	}
+}
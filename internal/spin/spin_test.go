@@ -0,0 +1,42 @@
+package spin
+
+import "testing"
+
+func TestWhile_StopsWhenFalse(t *testing.T) {
+	calls := 0
+	While(10, func() bool {
+		calls++
+		return calls < 3
+	})
+	if calls != 3 {
+		t.Errorf("Expected 3 checks, got %d", calls)
+	}
+}
+
+// Условие держится дольше бюджета: дальше проверяем через runtime.Gosched, пока не сбросится
+func TestWhile_PastBudget(t *testing.T) {
+	calls := 0
+	While(2, func() bool {
+		calls++
+		return calls < 100
+	})
+	if calls != 100 {
+		t.Errorf("Expected 100 checks, got %d", calls)
+	}
+}
+
+func TestWhile_ZeroBudget(t *testing.T) {
+	calls := 0
+	While(0, func() bool {
+		calls++
+		return false
+	})
+	if calls != 1 {
+		t.Errorf("Expected 1 check, got %d", calls)
+	}
+}
+
+func TestPause(t *testing.T) {
+	Pause(0)
+	Pause(1000)
+}